@@ -22,7 +22,10 @@ import (
 	"pulse/internal/db"
 	"pulse/internal/email"
 	"pulse/internal/handlers"
+	"pulse/internal/heartbeat"
+	"pulse/internal/metrics"
 	"pulse/internal/middleware"
+	"pulse/internal/notifier"
 	"pulse/internal/redis"
 	"pulse/internal/store"
 )
@@ -78,6 +81,8 @@ func main() {
 	r.RemoteIPHeaders = []string{"CF-Connecting-IP", "X-Real-IP", "X-Forwarded-For"}
 	r.ForwardedByClientIP = true
 
+	r.Use(metrics.Middleware())
+
 	r.Use(cors.New(cors.Config{
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "Authorization"},
@@ -86,21 +91,34 @@ func main() {
 		AllowAllOrigins:  true, //TODO: remove this later in favor of explicit control
 	}))
 
-	// Create alerter
-	a := alerter.New(s)
+	// Create notification dispatcher and alerter
+	dispatcher := notifier.NewDispatcher(s, emailService, cfg.FrontendURL)
+	a := alerter.New(s, dispatcher)
+
+	// Heartbeat runs recorded by the ping endpoints are copied to ClickHouse too
+	var runWriter *clickhouse.Writer
+	if chClient != nil {
+		runWriter = clickhouse.NewWriter(chClient)
+		defer runWriter.Close()
+	}
 
 	// Initialize handlers
 	projectHandler := handlers.NewProjectHandler(s)
-	checkHandler := handlers.NewCheckHandler(s)
-	checkRunHandler := handlers.NewCheckRunHandler(s, redisClient, a)
+	checkHandler := handlers.NewCheckHandler(s, redisClient)
+	checkRunHandler := handlers.NewCheckRunHandler(s, redisClient, chClient)
 	alertHandler := handlers.NewAlertHandler(s)
 	tagHandler := handlers.NewTagHandler(s)
+	channelHandler := handlers.NewChannelHandler(s, dispatcher)
+	maintenanceHandler := handlers.NewMaintenanceHandler(s)
 	regionHandler := handlers.NewRegionHandler(s)
 	authHandler := handlers.NewAuthHandler(s, cfg, emailService)
 	accountHandler := handlers.NewAccountHandler(s)
 	invitesHandler := handlers.NewInvitesHandler(s)
 	membersHandler := handlers.NewMembersHandler(s)
 	sessionHandler := handlers.NewSessionHandler(s)
+	heartbeatHandler := handlers.NewHeartbeatHandler(s, heartbeat.New(s, a, runWriter))
+	jobHandler := handlers.NewJobHandler(s, redisClient)
+	probeHandler := handlers.NewProbeHandler(s)
 
 	r.GET("/docs/:version", (func(c *gin.Context) {
 		version := c.Param("version")
@@ -175,6 +193,18 @@ func main() {
 		})
 	}))
 
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
+
+	// Probe metrics of a project, scraped with its metrics token
+	r.GET("/probe/metrics", probeHandler.Metrics)
+
+	// Heartbeat ping URLs, called by monitored jobs without authentication
+	for _, path := range []string{"/ping/:token", "/ping/:token/:signal"} {
+		r.GET(path, heartbeatHandler.Ping)
+		r.POST(path, heartbeatHandler.Ping)
+		r.HEAD(path, heartbeatHandler.Ping)
+	}
+
 	// API routes
 	api := r.Group("/api/internal")
 
@@ -210,27 +240,57 @@ func main() {
 		protected.GET("/projects/:projectId", projectHandler.GetProject)
 		protected.PUT("/projects/:projectId", projectHandler.UpdateProject)
 		protected.DELETE("/projects/:projectId", projectHandler.DeleteProject)
+		protected.POST("/projects/:projectId/metrics-token", probeHandler.RotateMetricsToken)
+		protected.DELETE("/projects/:projectId/metrics-token", probeHandler.DeleteMetricsToken)
 
 		protected.POST("/projects/:projectId/checks", checkHandler.CreateCheck)
 		protected.GET("/projects/:projectId/checks", checkHandler.ListChecks)
+		protected.POST("/projects/:projectId/checks/test", checkHandler.TestCheck)
 		protected.GET("/projects/:projectId/checks/status/counts", checkHandler.GetCheckCountsByStatus)
 		protected.GET("/projects/:projectId/checks/:checkId", checkHandler.GetCheck)
 		protected.PUT("/projects/:projectId/checks/:checkId", checkHandler.UpdateCheck)
 		protected.DELETE("/projects/:projectId/checks/:checkId", checkHandler.DeleteCheck)
 		protected.GET("/projects/:projectId/checks/:checkId/runs", checkRunHandler.ListCheckRuns)
 		protected.POST("/projects/:projectId/checks/:checkId/runs/trigger", checkRunHandler.TriggerCheckRun)
+		protected.GET("/projects/:projectId/checks/:checkId/runs/stream", checkRunHandler.StreamCheckRuns)
 		protected.GET("/projects/:projectId/checks/:checkId/runs/:runId", checkRunHandler.GetCheckRun)
 		protected.GET("/projects/:projectId/checks/:checkId/alerts", alertHandler.ListAlerts)
+		protected.GET("/projects/:projectId/checks/:checkId/incidents", alertHandler.ListCheckIncidents)
 		protected.GET("/projects/:projectId/checks/:checkId/uptime", checkRunHandler.GetCheckUptime)
 		protected.GET("/projects/:projectId/checks/:checkId/timings", checkRunHandler.GetCheckTimings)
 		protected.POST("/projects/:projectId/checks/:checkId/tags/:tagId", tagHandler.AddTagToCheck)
 		protected.DELETE("/projects/:projectId/checks/:checkId/tags/:tagId", tagHandler.RemoveTagFromCheck)
+		protected.POST("/projects/:projectId/checks/:checkId/channels/:channelId", channelHandler.AddChannelToCheck)
+		protected.DELETE("/projects/:projectId/checks/:checkId/channels/:channelId", channelHandler.RemoveChannelFromCheck)
 
 		protected.POST("/projects/:projectId/tags", tagHandler.CreateTag)
 		protected.GET("/projects/:projectId/tags", tagHandler.ListTags)
 		protected.POST("/projects/:projectId/tags/:tagId", tagHandler.AddTagToProject)
 		protected.DELETE("/projects/:projectId/tags/:tagId", tagHandler.RemoveTagFromProject)
 
+		protected.POST("/projects/:projectId/channels", channelHandler.CreateChannel)
+		protected.GET("/projects/:projectId/channels", channelHandler.ListChannels)
+		protected.GET("/projects/:projectId/channels/:channelId", channelHandler.GetChannel)
+		protected.PUT("/projects/:projectId/channels/:channelId", channelHandler.UpdateChannel)
+		protected.DELETE("/projects/:projectId/channels/:channelId", channelHandler.DeleteChannel)
+		protected.POST("/projects/:projectId/channels/:channelId/test", channelHandler.TestChannel)
+
+		protected.GET("/projects/:projectId/incidents", alertHandler.ListIncidents)
+		protected.GET("/projects/:projectId/incidents/:incidentId", alertHandler.GetIncident)
+		protected.POST("/projects/:projectId/incidents/:incidentId/acknowledge", alertHandler.AcknowledgeIncident)
+		protected.POST("/projects/:projectId/incidents/:incidentId/resolve", alertHandler.ResolveIncident)
+		protected.PUT("/projects/:projectId/incidents/:incidentId/assignee", alertHandler.AssignIncident)
+		protected.POST("/projects/:projectId/incidents/:incidentId/notes", alertHandler.AddIncidentNote)
+
+		protected.POST("/projects/:projectId/maintenance-windows", maintenanceHandler.CreateMaintenanceWindow)
+		protected.GET("/projects/:projectId/maintenance-windows", maintenanceHandler.ListMaintenanceWindows)
+		protected.GET("/projects/:projectId/maintenance-windows/:windowId", maintenanceHandler.GetMaintenanceWindow)
+		protected.PUT("/projects/:projectId/maintenance-windows/:windowId", maintenanceHandler.UpdateMaintenanceWindow)
+		protected.DELETE("/projects/:projectId/maintenance-windows/:windowId", maintenanceHandler.DeleteMaintenanceWindow)
+
+		protected.GET("/projects/:projectId/dead-jobs", jobHandler.ListDeadJobs)
+		protected.POST("/projects/:projectId/dead-jobs/:jobId/replay", jobHandler.ReplayDeadJob)
+
 		protected.POST("/projects/:projectId/invites", invitesHandler.CreateInvite)
 		protected.GET("/projects/:projectId/invites", invitesHandler.ListInvites)
 		protected.POST("/invites/accept", invitesHandler.AcceptInvite)
@@ -8,6 +8,7 @@ import (
 	"os/signal"
 	"strconv"
 	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
@@ -15,7 +16,12 @@ import (
 	"pulse/internal/clickhouse"
 	"pulse/internal/config"
 	"pulse/internal/db"
+	"pulse/internal/email"
+	"pulse/internal/heartbeat"
+	"pulse/internal/metrics"
+	"pulse/internal/notifier"
 	"pulse/internal/redis"
+	"pulse/internal/retention"
 	"pulse/internal/scheduler"
 	"pulse/internal/store"
 	"pulse/internal/worker"
@@ -67,17 +73,43 @@ func main() {
 	}
 	log.Printf("Worker running in region: %s (%s)", region.Name, region.Code)
 
-	// Create alerter
-	a := alerter.New(s)
+	// Initialize email service (optional, email channels are skipped without it)
+	emailService, err := email.NewService(cfg)
+	if err != nil {
+		log.Printf("Warning: Failed to initialize email service, email notifications disabled: %v", err)
+		emailService = nil
+	}
+
+	// Create notification dispatcher and alerter
+	dispatcher := notifier.NewDispatcher(s, emailService, cfg.FrontendURL)
+	a := alerter.New(s, dispatcher)
 
-	// Create scheduler with region code
-	sched := scheduler.New(s, redisClient, cfg.RegionCode, scheduler.DefaultConfig())
+	// Stored runs are copied to ClickHouse for analytics. The writer is closed
+	// after the scheduler and workers stopped, flushing the runs they wrote last.
+	var runWriter *clickhouse.Writer
+	if chClient != nil {
+		runWriter = clickhouse.NewWriter(chClient)
+		defer runWriter.Close()
+	}
+
+	// Create scheduler for this region
+	schedConfig := scheduler.DefaultConfig()
+	schedConfig.MaxConcurrent = cfg.SchedulerConcurrency
+	sched := scheduler.New(s, redisClient, heartbeat.New(s, a, runWriter), region, schedConfig)
 	sched.Start()
 	defer sched.Stop()
 
+	// Roll up and delete run history past each project's retention
+	retentionRunner := retention.New(s, redisClient)
+	retentionRunner.Start()
+	defer retentionRunner.Stop()
+
 	// Create and start workers
-	workerCount := 3
-	w := worker.New(s, redisClient, a, workerCount, region.ID)
+	workerConfig := worker.DefaultConfig()
+	workerConfig.MinWorkers = cfg.WorkerCount
+	workerConfig.MaxWorkers = cfg.WorkerMaxCount
+	workerConfig.ShutdownGrace = time.Duration(cfg.WorkerShutdownGrace) * time.Second
+	w := worker.New(s, redisClient, a, region, workerConfig, runWriter)
 	w.Start()
 	defer w.Stop()
 
@@ -89,6 +121,7 @@ func main() {
 			"status": "ok",
 		})
 	}))
+	healthRouter.GET("/metrics", gin.WrapH(metrics.Handler()))
 
 	// Calculate health port as API port + 1
 	apiPort, err := strconv.Atoi(cfg.Port)
@@ -3,33 +3,74 @@ package alerter
 import (
 	"log"
 
+	"github.com/google/uuid"
+
 	"pulse/internal/models"
+	"pulse/internal/notifier"
 	"pulse/internal/store"
 )
 
 type Alerter struct {
-	store *store.Store
+	store      *store.Store
+	dispatcher *notifier.Dispatcher
 }
 
-func New(s *store.Store) *Alerter {
+// New creates an alerter. dispatcher may be nil, in which case alerts are
+// recorded but no notifications are sent.
+func New(s *store.Store, dispatcher *notifier.Dispatcher) *Alerter {
 	return &Alerter{
-		store: s,
+		store:      s,
+		dispatcher: dispatcher,
 	}
 }
 
-// ProcessCheckResult processes the check result and creates an alert if the status has changed
+// ProcessCheckResult evaluates the check's alert policy against the recent runs of
+// every region and creates an alert when the confirmed status changes.
+// Muted checks and runs inside a maintenance window are ignored.
 // check is the check that was executed, run is the check run that was just created
 func (a *Alerter) ProcessCheckResult(check *models.Check, run *models.CheckRun) {
-	if check.LastStatus == models.CheckRunStatusUnknown {
+	// Muted checks and runs during maintenance never alert
+	if check.IsMuted || run.InMaintenance {
+		return
+	}
+
+	policy := PolicyFor(check)
+
+	history, err := a.store.GetRecentCheckRunsByRegion(check.ID, policy.HistorySize())
+	if err != nil {
+		log.Printf("Error loading run history for check %s: %v", check.ID, err)
+		return
+	}
+
+	regions := alertRegions(check)
+	regionIDs := make([]uuid.UUID, 0, len(regions))
+	for _, region := range regions {
+		regionIDs = append(regionIDs, region.ID)
+	}
+
+	status, ok := policy.Evaluate(regionIDs, history)
+	if !ok || status == check.AlertStatus {
 		return
 	}
 
-	if check.LastStatus == run.Status {
+	previous := check.AlertStatus
+	transitioned, err := a.store.TransitionCheckAlertStatus(check.ID, previous, status)
+	if err != nil {
+		log.Printf("Error updating alert status for check %s: %v", check.ID, err)
+		return
+	}
+	if !transitioned {
+		// Another region's run already handled this transition
+		return
+	}
+
+	// A new check coming up healthy is not worth an alert
+	if previous == models.CheckRunStatusUnknown && status == models.CheckRunStatusPassing {
 		return
 	}
 
 	alert := &models.Alert{
-		Status:    run.Status,
+		Status:    status,
 		RunID:     run.ID,
 		RegionID:  run.RegionID,
 		ProjectID: check.ProjectID,
@@ -41,5 +82,43 @@ func (a *Alerter) ProcessCheckResult(check *models.Check, run *models.CheckRun)
 		return
 	}
 
-	log.Printf("Alert created for check %s: status changed from %s to %s", check.Name, check.LastStatus, run.Status)
+	log.Printf("Alert created for check %s: status changed from %s to %s", check.Name, previous, status)
+
+	a.trackIncident(check, alert)
+	a.notify(check, run, alert, previous)
+}
+
+// notify sends the alert to the check's notification channels
+func (a *Alerter) notify(check *models.Check, run *models.CheckRun, alert *models.Alert, previous models.CheckRunStatus) {
+	if a.dispatcher == nil {
+		return
+	}
+
+	n := &notifier.Notification{
+		AlertID:        alert.ID,
+		RunID:          run.ID,
+		CheckID:        check.ID,
+		CheckName:      check.Name,
+		ProjectID:      check.ProjectID,
+		ProjectName:    check.Project.Name,
+		Status:         alert.Status,
+		PreviousStatus: previous,
+		URL:            a.dispatcher.CheckURL(check.ProjectID, check.ID),
+		OccurredAt:     run.RunStartedAt,
+	}
+
+	// Inverted passing runs keep their failure reason, which is not news in a recovery
+	if alert.Status != models.CheckRunStatusPassing {
+		n.FailureReason = run.FailureReason
+	}
+
+	for _, region := range check.Regions {
+		if region.ID == run.RegionID {
+			n.RegionCode = region.Code
+			n.RegionName = region.Name
+			break
+		}
+	}
+
+	a.dispatcher.DispatchAsync(n)
 }
@@ -0,0 +1,87 @@
+package alerter
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"pulse/internal/models"
+)
+
+// trackIncident attaches an alert to the check's open incident. The first
+// non-passing alert opens an incident and a passing alert resolves it.
+func (a *Alerter) trackIncident(check *models.Check, alert *models.Alert) {
+	incident, err := a.store.GetOpenIncidentByCheck(check.ID)
+	if err != nil {
+		log.Printf("Error loading open incident for check %s: %v", check.ID, err)
+		return
+	}
+
+	event := &models.IncidentEvent{
+		AlertID: &alert.ID,
+	}
+
+	switch {
+	case incident == nil && alert.Status == models.CheckRunStatusPassing:
+		// Nothing to recover from
+		return
+
+	case incident == nil:
+		incident = &models.Incident{
+			Status:    models.IncidentStatusOpen,
+			Severity:  alert.Status,
+			StartedAt: alert.CreatedAt,
+			ProjectID: check.ProjectID,
+			CheckID:   check.ID,
+		}
+		if err := a.store.CreateIncident(incident); err != nil {
+			log.Printf("Error opening incident for check %s: %v", check.ID, err)
+			return
+		}
+		event.Type = models.IncidentEventOpened
+		event.Message = fmt.Sprintf("%s is %s in %s", check.Name, alert.Status, regionName(check, alert))
+		log.Printf("Incident %s opened for check %s", incident.ID, check.Name)
+
+	case alert.Status == models.CheckRunStatusPassing:
+		resolved, err := a.store.ResolveIncident(incident.ID, time.Now().UTC())
+		if err != nil {
+			log.Printf("Error resolving incident %s: %v", incident.ID, err)
+			return
+		}
+		if !resolved {
+			// Resolved by a user in the meantime
+			return
+		}
+		event.Type = models.IncidentEventResolved
+		event.Message = fmt.Sprintf("%s recovered", check.Name)
+		log.Printf("Incident %s resolved for check %s", incident.ID, check.Name)
+
+	default:
+		if alert.Status == models.CheckRunStatusFailing && incident.Severity != models.CheckRunStatusFailing {
+			if err := a.store.SetIncidentSeverity(incident.ID, models.CheckRunStatusFailing); err != nil {
+				log.Printf("Error updating incident %s: %v", incident.ID, err)
+			}
+		}
+		event.Type = models.IncidentEventAlert
+		event.Message = fmt.Sprintf("%s is %s in %s", check.Name, alert.Status, regionName(check, alert))
+	}
+
+	event.IncidentID = incident.ID
+	if err := a.store.CreateIncidentEvent(event); err != nil {
+		log.Printf("Error adding event to incident %s: %v", incident.ID, err)
+	}
+
+	if err := a.store.SetAlertIncident(alert.ID, incident.ID); err != nil {
+		log.Printf("Error linking alert %s to incident %s: %v", alert.ID, incident.ID, err)
+	}
+}
+
+// regionName returns the name of the region the alert was raised from
+func regionName(check *models.Check, alert *models.Alert) string {
+	for _, region := range check.Regions {
+		if region.ID == alert.RegionID {
+			return region.Name
+		}
+	}
+	return alert.RegionID.String()
+}
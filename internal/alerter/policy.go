@@ -0,0 +1,111 @@
+package alerter
+
+import (
+	"github.com/google/uuid"
+
+	"pulse/internal/models"
+)
+
+// Policy decides when a check's status is confirmed enough to alert on
+type Policy struct {
+	// FailureThreshold is the number of consecutive non-passing runs before a region counts as down
+	FailureThreshold int
+	// RecoveryThreshold is the number of consecutive passing runs before a region counts as up
+	RecoveryThreshold int
+	// RegionQuorum is the number of regions that must be down before the check is down
+	RegionQuorum int
+}
+
+// PolicyFor builds the alert policy of a check, clamping values to sane bounds
+func PolicyFor(check *models.Check) Policy {
+	p := Policy{
+		FailureThreshold:  max(check.AlertFailureThreshold, 1),
+		RecoveryThreshold: max(check.AlertRecoveryThreshold, 1),
+		RegionQuorum:      max(check.AlertRegionQuorum, 1),
+	}
+	if regions := alertRegions(check); len(regions) > 0 {
+		p.RegionQuorum = min(p.RegionQuorum, len(regions))
+	}
+	return p
+}
+
+// alertRegions returns the regions the check's status is evaluated over. Heartbeat
+// runs are only recorded in the primary region, so the others would never recover.
+func alertRegions(check *models.Check) []models.Region {
+	if check.Type == models.CheckTypeHeartbeat {
+		if primary := check.PrimaryRegion(); primary != nil {
+			return []models.Region{*primary}
+		}
+	}
+	return check.Regions
+}
+
+// HistorySize is the number of recent runs per region needed to evaluate the policy
+func (p Policy) HistorySize() int {
+	return max(p.FailureThreshold, p.RecoveryThreshold)
+}
+
+// Evaluate returns the confirmed status of the check given the recent runs of each
+// region (newest first). It returns false when the history is not conclusive yet.
+func (p Policy) Evaluate(regionIDs []uuid.UUID, history map[uuid.UUID][]models.CheckRun) (models.CheckRunStatus, bool) {
+	var failing, degraded, passing int
+	for _, regionID := range regionIDs {
+		switch p.regionStatus(history[regionID]) {
+		case models.CheckRunStatusFailing:
+			failing++
+		case models.CheckRunStatusDegraded:
+			degraded++
+		case models.CheckRunStatusPassing:
+			passing++
+		}
+	}
+
+	switch {
+	case failing >= p.RegionQuorum:
+		return models.CheckRunStatusFailing, true
+	case failing+degraded >= p.RegionQuorum:
+		return models.CheckRunStatusDegraded, true
+	case passing > len(regionIDs)-p.RegionQuorum:
+		// Not enough regions left to reach quorum
+		return models.CheckRunStatusPassing, true
+	default:
+		return models.CheckRunStatusUnknown, false
+	}
+}
+
+// regionStatus returns the confirmed status of a single region, or unknown if the
+// latest runs don't form a long enough streak
+func (p Policy) regionStatus(runs []models.CheckRun) models.CheckRunStatus {
+	if len(runs) == 0 {
+		return models.CheckRunStatusUnknown
+	}
+
+	if runs[0].Status == models.CheckRunStatusPassing {
+		if len(runs) < p.RecoveryThreshold {
+			return models.CheckRunStatusUnknown
+		}
+		for _, run := range runs[:p.RecoveryThreshold] {
+			if run.Status != models.CheckRunStatusPassing {
+				return models.CheckRunStatusUnknown
+			}
+		}
+		return models.CheckRunStatusPassing
+	}
+
+	if len(runs) < p.FailureThreshold {
+		return models.CheckRunStatusUnknown
+	}
+
+	// A streak of failing runs is failing, a streak mixing degraded and failing is degraded
+	status := models.CheckRunStatusFailing
+	for _, run := range runs[:p.FailureThreshold] {
+		switch run.Status {
+		case models.CheckRunStatusFailing:
+		case models.CheckRunStatusDegraded:
+			status = models.CheckRunStatusDegraded
+		default:
+			return models.CheckRunStatusUnknown
+		}
+	}
+	return status
+}
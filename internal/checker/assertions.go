@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -23,6 +24,18 @@ const (
 	AssertionSourceResponseBodyText AssertionSource = "response_body_text"
 	AssertionSourceResponseBodyJSON AssertionSource = "response_body_json"
 	AssertionSourceResponseHeaders  AssertionSource = "response_headers"
+
+	// Structured body sources. The JSON schema source validates the body against the
+	// schema given as target; the HTML and XML sources select values with the CSS
+	// selector or XPath expression given as property.
+	AssertionSourceResponseBodyJSONSchema AssertionSource = "response_body_json_schema"
+	AssertionSourceResponseBodyHTML       AssertionSource = "response_body_html"
+	AssertionSourceResponseBodyXML        AssertionSource = "response_body_xml"
+
+	// TLS sources, available to HTTP and TCP checks over TLS
+	AssertionSourceSSLDaysRemaining  AssertionSource = "ssl_days_remaining"
+	AssertionSourceTLSVersion        AssertionSource = "tls_version"
+	AssertionSourceCertificateIssuer AssertionSource = "certificate_issuer"
 )
 
 // AssertionComparison represents the comparison operation to perform.
@@ -39,6 +52,8 @@ const (
 	AssertionComparisonIsLessThanOrEqualTo    AssertionComparison = "is_less_than_or_equal_to"
 	AssertionComparisonIsGreaterThan          AssertionComparison = "is_greater_than"
 	AssertionComparisonIsGreaterThanOrEqualTo AssertionComparison = "is_greater_than_or_equal_to"
+	AssertionComparisonMatchesRegex           AssertionComparison = "matches_regex"
+	AssertionComparisonNotMatchesRegex        AssertionComparison = "not_matches_regex"
 )
 
 var (
@@ -69,6 +84,7 @@ type AssertionResult struct {
 type responseContext struct {
 	resp         *http.Response
 	responseTime time.Duration
+	tls          *TLSInfo
 	bodyText     string
 	bodyJSON     interface{}
 	bodyRead     bool
@@ -78,16 +94,29 @@ type responseContext struct {
 
 // ProcessAssertions evaluates a list of assertions against an HTTP response.
 func ProcessAssertions(assertions datatypes.JSON, resp *http.Response, responseTime time.Duration) ([]AssertionResult, error) {
+	return evaluateAssertions(assertions, &responseContext{
+		resp:         resp,
+		responseTime: responseTime,
+		tls:          buildTLSInfo(resp.TLS, time.Now()),
+	})
+}
+
+// ProcessConnectionAssertions evaluates a list of assertions against a connection
+// without an HTTP response, such as a TCP check. tlsInfo is nil for plain connections.
+func ProcessConnectionAssertions(assertions datatypes.JSON, tlsInfo *TLSInfo, responseTime time.Duration) ([]AssertionResult, error) {
+	return evaluateAssertions(assertions, &responseContext{
+		responseTime: responseTime,
+		tls:          tlsInfo,
+	})
+}
+
+// evaluateAssertions decodes and evaluates assertions against a response context.
+func evaluateAssertions(assertions datatypes.JSON, ctx *responseContext) ([]AssertionResult, error) {
 	var assertionsList []Assertion
 	if err := json.Unmarshal(assertions, &assertionsList); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal assertions: %w", err)
 	}
 
-	ctx := &responseContext{
-		resp:         resp,
-		responseTime: responseTime,
-	}
-
 	results := make([]AssertionResult, len(assertionsList))
 	for i, assertion := range assertionsList {
 		results[i] = ctx.evaluateAssertion(assertion)
@@ -96,6 +125,44 @@ func ProcessAssertions(assertions datatypes.JSON, resp *http.Response, responseT
 	return results, nil
 }
 
+// ValidateAssertions checks that a check's assertions decode and that their
+// patterns, schemas, selectors and XPath expressions compile.
+func ValidateAssertions(assertions datatypes.JSON) error {
+	if len(assertions) == 0 {
+		return nil
+	}
+
+	var assertionsList []Assertion
+	if err := json.Unmarshal(assertions, &assertionsList); err != nil {
+		return fmt.Errorf("invalid assertions: %w", err)
+	}
+
+	for i, a := range assertionsList {
+		switch AssertionComparison(a.Comparison) {
+		case AssertionComparisonMatchesRegex, AssertionComparisonNotMatchesRegex:
+			if _, err := regexp.Compile(a.Target); err != nil {
+				return fmt.Errorf("assertion %d: invalid regex: %w", i+1, err)
+			}
+		}
+
+		switch a.Source {
+		case AssertionSourceResponseBodyJSONSchema:
+			if _, err := compileSchema(a.Target); err != nil {
+				return fmt.Errorf("assertion %d: %w", i+1, err)
+			}
+		case AssertionSourceResponseBodyHTML, AssertionSourceResponseBodyXML:
+			if a.Property == nil || *a.Property == "" {
+				return fmt.Errorf("assertion %d: %s needs a property", i+1, a.Source)
+			}
+			if err := validateSelector(a.Source, *a.Property); err != nil {
+				return fmt.Errorf("assertion %d: %w", i+1, err)
+			}
+		}
+	}
+
+	return nil
+}
+
 // readBody reads and caches the response body.
 func (rc *responseContext) readBody() error {
 	if rc.bodyRead {
@@ -141,6 +208,14 @@ func (rc *responseContext) getBodyJSON() (interface{}, error) {
 func (rc *responseContext) evaluateAssertion(a Assertion) AssertionResult {
 	result := AssertionResult{Assertion: a}
 
+	// HTTP sources need a response, TLS sources a TLS connection
+	if rc.resp == nil && isHTTPSource(a.Source) {
+		return result
+	}
+	if rc.tls == nil && isTLSSource(a.Source) {
+		return result
+	}
+
 	switch a.Source {
 	case AssertionSourceStatusCode:
 		result.Received = rc.resp.StatusCode
@@ -177,6 +252,37 @@ func (rc *responseContext) evaluateAssertion(a Assertion) AssertionResult {
 		result.Received = value
 		result.Passed = evaluateDynamic(a.Comparison, value, a.Target)
 
+	case AssertionSourceResponseBodyJSONSchema:
+		if err := rc.readBody(); err != nil {
+			return result
+		}
+		validationErrors, err := validateJSONSchema(rc.bodyText, a.Target)
+		if err != nil {
+			result.Received = err.Error()
+			return result
+		}
+		if len(validationErrors) > 0 {
+			result.Received = validationErrors
+		}
+		result.Passed = len(validationErrors) == 0
+
+	case AssertionSourceResponseBodyHTML, AssertionSourceResponseBodyXML:
+		if err := rc.readBody(); err != nil || a.Property == nil {
+			return result
+		}
+		var value interface{}
+		var err error
+		if a.Source == AssertionSourceResponseBodyHTML {
+			value, err = selectHTML(rc.bodyText, *a.Property)
+		} else {
+			value, err = evaluateXPath(rc.bodyText, *a.Property)
+		}
+		if err != nil {
+			return result
+		}
+		result.Received = value
+		result.Passed = evaluateDynamic(a.Comparison, value, a.Target)
+
 	case AssertionSourceResponseHeaders:
 		headersMap := headersToMap(rc.resp.Header)
 		value, err := resolvePath(headersMap, a.Property)
@@ -188,6 +294,22 @@ func (rc *responseContext) evaluateAssertion(a Assertion) AssertionResult {
 		result.Received = value
 		result.Passed = evaluateDynamic(a.Comparison, value, a.Target)
 
+	case AssertionSourceSSLDaysRemaining:
+		result.Received = rc.tls.DaysRemaining
+		result.Passed = evaluateNumber(a.Comparison, float64(rc.tls.DaysRemaining), a.Target)
+
+	case AssertionSourceTLSVersion:
+		result.Received = rc.tls.Version
+		result.Passed = evaluateString(a.Comparison, rc.tls.Version, a.Target)
+
+	case AssertionSourceCertificateIssuer:
+		if len(rc.tls.Chain) == 0 {
+			return result
+		}
+		issuer := rc.tls.Chain[0].Issuer
+		result.Received = issuer
+		result.Passed = evaluateString(a.Comparison, issuer, a.Target)
+
 	default:
 		result.Received = nil
 		result.Passed = false
@@ -196,6 +318,34 @@ func (rc *responseContext) evaluateAssertion(a Assertion) AssertionResult {
 	return result
 }
 
+// isHTTPSource reports whether an assertion source reads the HTTP response.
+func isHTTPSource(source AssertionSource) bool {
+	switch source {
+	case AssertionSourceStatusCode,
+		AssertionSourceResponseBodyText,
+		AssertionSourceResponseBodyJSON,
+		AssertionSourceResponseBodyJSONSchema,
+		AssertionSourceResponseBodyHTML,
+		AssertionSourceResponseBodyXML,
+		AssertionSourceResponseHeaders:
+		return true
+	default:
+		return false
+	}
+}
+
+// isTLSSource reports whether an assertion source reads the TLS connection state.
+func isTLSSource(source AssertionSource) bool {
+	switch source {
+	case AssertionSourceSSLDaysRemaining,
+		AssertionSourceTLSVersion,
+		AssertionSourceCertificateIssuer:
+		return true
+	default:
+		return false
+	}
+}
+
 // evaluateNumber performs numeric comparisons.
 func evaluateNumber(comparison string, actual float64, expected interface{}) bool {
 	expectedNum, ok := toFloat(expected)
@@ -238,6 +388,10 @@ func evaluateString(comparison string, actual string, expected interface{}) bool
 		return actual == ""
 	case AssertionComparisonIsNotEmpty:
 		return actual != ""
+	case AssertionComparisonMatchesRegex:
+		return matchesRegex(actual, expectedStr)
+	case AssertionComparisonNotMatchesRegex:
+		return !matchesRegex(actual, expectedStr)
 	default:
 		return false
 	}
@@ -270,6 +424,10 @@ func evaluateDynamic(comparison string, actual, expected interface{}) bool {
 		return isEmpty(actual)
 	case AssertionComparisonIsNotEmpty:
 		return !isEmpty(actual)
+	case AssertionComparisonMatchesRegex:
+		return matchesValue(actual, fmt.Sprint(expected))
+	case AssertionComparisonNotMatchesRegex:
+		return !matchesValue(actual, fmt.Sprint(expected))
 	default:
 		return false
 	}
@@ -357,6 +515,23 @@ func containsValue(actual, expected interface{}) bool {
 	}
 }
 
+// matchesValue checks if actual, or any item of a list, matches the pattern.
+func matchesValue(actual interface{}, pattern string) bool {
+	switch v := actual.(type) {
+	case string:
+		return matchesRegex(v, pattern)
+	case []interface{}:
+		for _, item := range v {
+			if matchesValue(item, pattern) {
+				return true
+			}
+		}
+		return false
+	default:
+		return matchesRegex(fmt.Sprint(actual), pattern)
+	}
+}
+
 // isEmpty checks if a value is considered empty.
 func isEmpty(val interface{}) bool {
 	if val == nil {
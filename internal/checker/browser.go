@@ -0,0 +1,12 @@
+package checker
+
+import "pulse/internal/models"
+
+func init() {
+	// Browser checks can be configured, but there is no executor to run their
+	// Playwright scripts yet, so their runs fail as unsupported
+	RegisterType(Type{
+		Name:       models.CheckTypeBrowser,
+		Thresholds: defaultThresholds,
+	})
+}
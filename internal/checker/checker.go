@@ -1,19 +1,33 @@
 package checker
 
 import (
+	"context"
+	"errors"
 	"time"
 
 	"pulse/internal/models"
 )
 
-// Execute runs a check and returns the result
-func Execute(check *models.Check) Result {
+// errRunTimeout is the cause of a run cut short by the check's RetriesTimeout
+var errRunTimeout = errors.New("run timeout exceeded")
+
+// Execute runs a check and returns the result. The whole run, retries included,
+// is bounded by the check's RetriesTimeout; a run that exceeds it fails with
+// run_timeout. When ctx is cancelled the current attempt is aborted and no further
+// attempts are made.
+func Execute(ctx context.Context, check *models.Check) Result {
+	if timeout := check.RetriesTimeoutDuration(); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, timeout, errRunTimeout)
+		defer cancel()
+	}
+
 	attempts := computeAttempts(check)
 	var result Result
 	var previousDelay time.Duration
 
 	for attempt := 0; attempt < attempts; attempt++ {
-		result = executeOnce(check)
+		result = executeOnce(ctx, check)
 		if result.Status == models.CheckRunStatusPassing {
 			return result
 		}
@@ -21,13 +35,46 @@ func Execute(check *models.Check) Result {
 		if attempt == attempts-1 {
 			break
 		}
+		if ctx.Err() != nil {
+			return abortedResult(ctx, result)
+		}
 
 		delay := computeRetryDelay(check, attempt, previousDelay)
 		previousDelay = delay
 		if delay > 0 {
-			time.Sleep(delay)
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return abortedResult(ctx, result)
+			case <-timer.C:
+			}
+		}
+	}
+
+	// The last attempt may have been aborted rather than failed on its own
+	if result.Error != nil {
+		return abortedResult(ctx, result)
+	}
+	return result
+}
+
+// abortedResult marks a failed result of a run whose context ended as a run
+// timeout, or as cancelled. Results of runs that weren't cut short are unchanged.
+func abortedResult(ctx context.Context, result Result) Result {
+	if ctx.Err() == nil || result.Status == models.CheckRunStatusPassing {
+		return result
+	}
+
+	if errors.Is(context.Cause(ctx), errRunTimeout) {
+		result.Status = models.CheckRunStatusFailing
+		result.FailureReason = failureReasonPtr(models.FailureRunTimeout)
+		if result.Error == nil {
+			result.Error = errRunTimeout
 		}
+		return result
 	}
 
+	result.Error = ctx.Err()
 	return result
 }
@@ -33,6 +33,45 @@ type dnsTimingTracker struct {
 	responseEnd  time.Time
 }
 
+func init() {
+	RegisterType(Type{
+		Name:     models.CheckTypeDNS,
+		Execute:  ExecuteDNSCheck,
+		Validate: validateDNSCheck,
+		Thresholds: Thresholds{
+			Degraded:     1000,
+			DegradedUnit: models.UnitTypeMs,
+			Failed:       3000,
+			FailedUnit:   models.UnitTypeMs,
+		},
+	})
+}
+
+// validateDNSCheck checks the record type and resolver of a DNS check
+func validateDNSCheck(check *models.Check) error {
+	if check.DNSRecordType == nil {
+		return fmt.Errorf("dns_record_type is required")
+	}
+	switch *check.DNSRecordType {
+	case models.DNSRecordTypeA, models.DNSRecordTypeAAAA, models.DNSRecordTypeCNAME, models.DNSRecordTypeMX,
+		models.DNSRecordTypeNS, models.DNSRecordTypeSOA, models.DNSRecordTypeSRV, models.DNSRecordTypeTXT:
+	default:
+		return fmt.Errorf("unsupported dns_record_type %q", *check.DNSRecordType)
+	}
+
+	if check.DNSResolverProtocol != nil {
+		switch *check.DNSResolverProtocol {
+		case models.DNSResolverProtocolUDP, models.DNSResolverProtocolTCP:
+		default:
+			return fmt.Errorf("unsupported dns_resolver_protocol %q", *check.DNSResolverProtocol)
+		}
+	}
+	if check.DNSResolverPort != nil && (*check.DNSResolverPort < 1 || *check.DNSResolverPort > 65535) {
+		return fmt.Errorf("dns_resolver_port must be between 1 and 65535")
+	}
+	return nil
+}
+
 // ExecuteDNSCheck performs a DNS check and returns the result.
 // It handles DNS queries, timing tracking, and result building.
 func ExecuteDNSCheck(ctx context.Context, check *models.Check) Result {
@@ -0,0 +1,125 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"pulse/internal/models"
+)
+
+// PingKind identifies which heartbeat ping URL was called
+type PingKind string
+
+const (
+	PingSuccess PingKind = "success" // /ping/:token or exit code 0
+	PingStart   PingKind = "start"   // /ping/:token/start
+	PingFail    PingKind = "fail"    // /ping/:token/fail or a non-zero exit code
+)
+
+// Ping is a single call to a heartbeat check's ping URL
+type Ping struct {
+	Kind       PingKind
+	ExitCode   *int
+	Body       string // request body, e.g. the job's log output
+	Method     string
+	RemoteAddr string
+	UserAgent  string
+	ReceivedAt time.Time
+}
+
+func init() {
+	RegisterType(Type{
+		Name:       models.CheckTypeHeartbeat,
+		Execute:    ExecuteHeartbeatCheck,
+		Validate:   validateHeartbeatCheck,
+		Thresholds: defaultThresholds,
+		Passive:    true,
+	})
+}
+
+// validateHeartbeatCheck checks the schedule of a heartbeat check. Pings are
+// expected every interval, so cron schedules aren't supported.
+func validateHeartbeatCheck(check *models.Check) error {
+	if check.HeartbeatGraceDuration() <= 0 {
+		return fmt.Errorf("invalid heartbeat_grace")
+	}
+	if check.Cron != nil {
+		return fmt.Errorf("cron is not supported for heartbeat checks")
+	}
+	return nil
+}
+
+// ExecuteHeartbeatCheck evaluates a heartbeat check at the current time. Heartbeat
+// checks are not probed, so the check passes until its next ping is overdue.
+func ExecuteHeartbeatCheck(ctx context.Context, check *models.Check) Result {
+	now := time.Now().UTC()
+	deadline := check.HeartbeatDeadline()
+
+	result := Result{
+		Status:           models.CheckRunStatusPassing,
+		RequestStartedAt: now,
+		ResponseEndedAt:  now,
+		AssertionResults: emptyJSONArray(),
+		PlaywrightReport: emptyJSONObject(),
+		NetworkTimings:   emptyJSONObject(),
+		Response: mustMarshalJSON(map[string]interface{}{
+			"last_ping_at":         check.LastPingAt,
+			"heartbeat_started_at": check.HeartbeatStartedAt,
+			"deadline":             deadline,
+		}),
+	}
+
+	if now.After(deadline) {
+		result.Status = models.CheckRunStatusFailing
+		result.FailureReason = failureReasonPtr(models.FailureHeartbeatMissed)
+		result.Error = fmt.Errorf("no ping received since %s", deadline.Format(time.RFC3339))
+	}
+
+	return result
+}
+
+// EvaluateHeartbeat evaluates a heartbeat check once, without retries, applying
+// the check's ShouldFail flag
+func EvaluateHeartbeat(check *models.Check) Result {
+	return applyShouldFail(check, ExecuteHeartbeatCheck(context.Background(), check))
+}
+
+// HeartbeatPingResult converts a finishing ping into a check result. The request
+// timeline spans from the job's /start signal, if any, to the ping.
+func HeartbeatPingResult(check *models.Check, ping Ping) Result {
+	startedAt := ping.ReceivedAt
+	if check.HeartbeatStartedAt != nil && (check.LastPingAt == nil || check.HeartbeatStartedAt.After(*check.LastPingAt)) {
+		startedAt = *check.HeartbeatStartedAt
+	}
+
+	result := Result{
+		Status:            models.CheckRunStatusPassing,
+		RequestStartedAt:  startedAt,
+		ResponseEndedAt:   ping.ReceivedAt,
+		IPAddress:         ping.RemoteAddr,
+		ResponseSizeBytes: int64(len(ping.Body)),
+		AssertionResults:  emptyJSONArray(),
+		PlaywrightReport:  emptyJSONObject(),
+		NetworkTimings:    emptyJSONObject(),
+		Response: mustMarshalJSON(map[string]interface{}{
+			"kind":       ping.Kind,
+			"exit_code":  ping.ExitCode,
+			"method":     ping.Method,
+			"user_agent": ping.UserAgent,
+			"body":       ping.Body,
+		}),
+	}
+
+	if ping.Kind == PingFail {
+		result.Status = models.CheckRunStatusFailing
+		result.FailureReason = failureReasonPtr(models.FailureHeartbeatFailed)
+		if ping.ExitCode != nil {
+			result.Error = fmt.Errorf("job exited with code %d", *ping.ExitCode)
+		} else {
+			result.Error = fmt.Errorf("job reported a failure")
+		}
+	}
+
+	return applyShouldFail(check, result)
+}
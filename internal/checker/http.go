@@ -55,6 +55,30 @@ type httpCheckExecutor struct {
 	ipAddress        string
 	responseBody     []byte
 	responseHeaders  map[string][]string
+	statusCode       int
+	tlsInfo          *TLSInfo
+	scripts          *scriptRunner
+}
+
+func init() {
+	RegisterType(Type{
+		Name:       models.CheckTypeHTTP,
+		Execute:    ExecuteHTTPCheck,
+		Thresholds: defaultThresholds,
+		AssertionSources: []AssertionSource{
+			AssertionSourceStatusCode,
+			AssertionSourceResponseTimeMs,
+			AssertionSourceResponseBodyText,
+			AssertionSourceResponseBodyJSON,
+			AssertionSourceResponseHeaders,
+			AssertionSourceResponseBodyJSONSchema,
+			AssertionSourceResponseBodyHTML,
+			AssertionSourceResponseBodyXML,
+			AssertionSourceSSLDaysRemaining,
+			AssertionSourceTLSVersion,
+			AssertionSourceCertificateIssuer,
+		},
+	})
 }
 
 // ExecuteHTTPCheck performs an HTTP check and returns the result.
@@ -107,6 +131,7 @@ func newHTTPCheckExecutor(check *models.Check) *httpCheckExecutor {
 		timings:          &timingTracker{},
 		responseSize:     0,
 		connectionReused: false,
+		scripts:          &scriptRunner{},
 	}
 }
 
@@ -207,6 +232,15 @@ func createIPVersionDialer(ipVersion models.IPVersionType) func(context.Context,
 
 // execute runs the HTTP check and returns the result.
 func (e *httpCheckExecutor) execute(ctx context.Context) Result {
+	// Run the pre-script, which may change the request
+	if e.check.PreScript != nil && *e.check.PreScript != "" {
+		check, err := e.scripts.runPreScript(ctx, e.check)
+		if err != nil {
+			return e.createScriptErrorResult(e.createErrorResult(err), err)
+		}
+		e.check = check
+	}
+
 	// Build request
 	req, err := e.buildRequest(ctx)
 	if err != nil {
@@ -240,6 +274,8 @@ func (e *httpCheckExecutor) execute(ctx context.Context) Result {
 	e.responseSize = int64(len(bodyBytes))
 
 	// Store response body and headers for result
+	e.statusCode = resp.StatusCode
+	e.tlsInfo = buildTLSInfo(resp.TLS, e.timings.responseEnd)
 	e.responseBody = bodyBytes
 	e.responseHeaders = make(map[string][]string)
 	for k, v := range resp.Header {
@@ -256,6 +292,20 @@ func (e *httpCheckExecutor) execute(ctx context.Context) Result {
 		return e.createErrorResult(fmt.Errorf("%w: %v", ErrAssertionProcessing, err))
 	}
 
+	// Run the post-script, which may add assertion results
+	if e.check.PostScript != nil && *e.check.PostScript != "" {
+		scriptResults, err := e.scripts.runPostScript(ctx, e.check, ScriptResponse{
+			StatusCode: resp.StatusCode,
+			Headers:    headersToMap(resp.Header),
+			Body:       string(bodyBytes),
+			TimeMs:     responseTime.Milliseconds(),
+		})
+		assertionResults = append(assertionResults, scriptResults...)
+		if err != nil {
+			return e.createScriptErrorResult(e.buildResult(resp, assertionResults), err)
+		}
+	}
+
 	// Build result with timestamps
 	return e.buildResult(resp, assertionResults)
 }
@@ -291,6 +341,11 @@ func (e *httpCheckExecutor) buildURL() (string, error) {
 		Host:   fmt.Sprintf("%s:%d", e.check.Host, e.check.Port),
 		Path:   e.check.Path,
 	}
+	// Paths may hold escapes, such as those of substituted step variables, which
+	// are sent as they are
+	if unescaped, err := url.PathUnescape(e.check.Path); err == nil {
+		u.Path, u.RawPath = unescaped, e.check.Path
+	}
 
 	if e.check.Secure {
 		u.Scheme = "https"
@@ -483,6 +538,10 @@ func (e *httpCheckExecutor) buildNetworkTimings() map[string]interface{} {
 			timings["tls_duration_us"] = us
 		}
 	}
+	if e.tlsInfo != nil {
+		timings["tls_version"] = e.tlsInfo.Version
+		timings["tls_cipher_suite"] = e.tlsInfo.CipherSuite
+	}
 	// Request send duration: request_sent - tls_done (or request_sent - request_start if no TLS)
 	// Only compute if request_sent is after tls_done (or request_start if no TLS)
 	if !e.timings.requestSent.IsZero() {
@@ -575,6 +634,9 @@ func (e *httpCheckExecutor) buildResult(resp *http.Response, assertionResults []
 		}
 	}
 
+	// Escalate when the certificate chain is about to expire
+	status, failureReason = applyCertificateExpiry(e.check, e.tlsInfo, status, failureReason)
+
 	// Response status (nullable)
 	var responseStatus *int32
 	if resp != nil {
@@ -584,7 +646,7 @@ func (e *httpCheckExecutor) buildResult(resp *http.Response, assertionResults []
 
 	// Build response object using unified builder
 	rb := &ResponseBuilder{}
-	responseData := rb.BuildHTTPResponse(e.responseHeaders, e.responseBody, resp.Header.Get("Content-Type"), resp.Proto)
+	responseData := rb.BuildHTTPResponse(e.responseHeaders, e.responseBody, resp.Header.Get("Content-Type"), resp.Proto, e.tlsInfo)
 
 	return Result{
 		Status:            status,
@@ -601,10 +663,19 @@ func (e *httpCheckExecutor) buildResult(resp *http.Response, assertionResults []
 		PlaywrightReport:  emptyJSONObject(),
 		NetworkTimings:    mustMarshalJSON(networkTimings),
 		Response:          responseData,
+		ScriptLogs:        e.scripts.logsJSON(),
 		Error:             nil,
 	}
 }
 
+// createScriptErrorResult marks a result as failed by a pre- or post-script error.
+func (e *httpCheckExecutor) createScriptErrorResult(result Result, err error) Result {
+	result.Status = models.CheckRunStatusFailing
+	result.FailureReason = failureReasonPtr(models.FailureScript)
+	result.Error = err
+	return result
+}
+
 
 // createErrorResult creates a result for a failed check.
 func (e *httpCheckExecutor) createErrorResult(err error) Result {
@@ -635,6 +706,7 @@ func (e *httpCheckExecutor) createErrorResult(err error) Result {
 		PlaywrightReport:  emptyJSONObject(),
 		NetworkTimings:    emptyJSONObject(),
 		Response:          EmptyResponse(),
+		ScriptLogs:        e.scripts.logsJSON(),
 		Error:             err,
 	}
 }
@@ -690,6 +762,9 @@ func (e *httpCheckExecutor) determineFailureReason(resp *http.Response, response
 	// Check assertions first
 	for _, result := range assertionResults {
 		if !result.Passed {
+			if result.Source == AssertionSourceResponseBodyJSONSchema {
+				return failureReasonPtr(models.FailureSchemaValidation)
+			}
 			return failureReasonPtr(models.FailureAssertionFailed)
 		}
 	}
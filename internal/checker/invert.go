@@ -0,0 +1,33 @@
+package checker
+
+import (
+	"pulse/internal/models"
+)
+
+// applyShouldFail inverts the verdict of checks that are expected to fail, such as
+// an admin port that must be unreachable. A failure becomes a pass that keeps its
+// original failure reason; a pass or degraded run becomes an unexpected success.
+// Internal and script errors are not inverted, they say nothing about the target.
+func applyShouldFail(check *models.Check, result Result) Result {
+	if !check.ShouldFail {
+		return result
+	}
+
+	if result.FailureReason != nil {
+		switch *result.FailureReason {
+		case models.FailureAgent, models.FailureSerialization, models.FailureUnknown, models.FailureScript:
+			return result
+		}
+	}
+
+	result.Inverted = true
+	if result.Status == models.CheckRunStatusFailing {
+		result.Status = models.CheckRunStatusPassing
+		result.Error = nil
+		return result
+	}
+
+	result.Status = models.CheckRunStatusFailing
+	result.FailureReason = failureReasonPtr(models.FailureUnexpectedSuccess)
+	return result
+}
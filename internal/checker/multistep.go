@@ -0,0 +1,315 @@
+package checker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/datatypes"
+
+	"pulse/internal/models"
+)
+
+var (
+	// ErrNoSteps is returned when a multi-step check has no steps.
+	ErrNoSteps = errors.New("multi-step check has no steps")
+	// ErrUndefinedVariable is returned when a step references a variable no earlier step extracted.
+	ErrUndefinedVariable = errors.New("undefined variable")
+)
+
+// variablePattern matches {{name}} references to extracted variables.
+var variablePattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// stepMethods are the HTTP methods a step may use. Steps without a method use GET.
+var stepMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodOptions: true,
+}
+
+// Step is a single request of a multi-step API check. Steps share the check's
+// host, port, TLS and redirect settings, and run in order.
+type Step struct {
+	Name        string         `json:"name"`
+	Method      string         `json:"method"`
+	Path        string         `json:"path"`
+	QueryParams datatypes.JSON `json:"query_params,omitempty"`
+	Headers     datatypes.JSON `json:"headers,omitempty"`
+	Body        datatypes.JSON `json:"body,omitempty"`
+	Assertions  datatypes.JSON `json:"assertions,omitempty"`
+	Extract     []Extraction   `json:"extract,omitempty"`
+}
+
+// Extraction stores a value of a step's response in a variable that later steps
+// reference as {{name}} in their path, query params, headers or body.
+type Extraction struct {
+	Name     string          `json:"name"`
+	Source   AssertionSource `json:"source"` // response_body_json, response_headers or status_code
+	Property *string         `json:"property,omitempty"`
+}
+
+// StepResult is the outcome of one step, stored in the run's step results.
+type StepResult struct {
+	Name             string                 `json:"name"`
+	Status           models.CheckRunStatus  `json:"status"`
+	FailureReason    *models.FailureReason  `json:"failure_reason,omitempty"`
+	ResponseStatus   *int32                 `json:"response_status_code,omitempty"`
+	RequestStartedAt time.Time              `json:"request_started_at"`
+	FirstByteAt      time.Time              `json:"first_byte_at"`
+	ResponseEndedAt  time.Time              `json:"response_ended_at"`
+	NetworkTimings   datatypes.JSON         `json:"network_timings"`
+	AssertionResults datatypes.JSON         `json:"assertion_results"`
+	Extracted        map[string]interface{} `json:"extracted,omitempty"`
+	Error            string                 `json:"error,omitempty"`
+}
+
+// ParseSteps decodes and validates the steps of a multi-step check.
+func ParseSteps(raw datatypes.JSON) ([]Step, error) {
+	if len(raw) == 0 {
+		return nil, ErrNoSteps
+	}
+
+	var steps []Step
+	if err := json.Unmarshal(raw, &steps); err != nil {
+		return nil, fmt.Errorf("invalid steps: %w", err)
+	}
+	if len(steps) == 0 {
+		return nil, ErrNoSteps
+	}
+
+	defined := make(map[string]bool)
+	for i, step := range steps {
+		if step.Path == "" {
+			return nil, fmt.Errorf("step %d: path is required", i+1)
+		}
+		if step.Method != "" && !stepMethods[step.Method] {
+			return nil, fmt.Errorf("step %d: unsupported method %q", i+1, step.Method)
+		}
+		if err := ValidateAssertions(step.Assertions); err != nil {
+			return nil, fmt.Errorf("step %d: %w", i+1, err)
+		}
+
+		// Variables must be extracted by an earlier step
+		for _, part := range []string{step.Path, string(step.QueryParams), string(step.Headers), string(step.Body)} {
+			for _, match := range variablePattern.FindAllStringSubmatch(part, -1) {
+				if !defined[match[1]] {
+					return nil, fmt.Errorf("step %d: %w: %s", i+1, ErrUndefinedVariable, match[1])
+				}
+			}
+		}
+
+		for _, extraction := range step.Extract {
+			if !variablePattern.MatchString("{{" + extraction.Name + "}}") {
+				return nil, fmt.Errorf("step %d: invalid variable name %q", i+1, extraction.Name)
+			}
+			switch extraction.Source {
+			case AssertionSourceResponseBodyJSON, AssertionSourceResponseHeaders:
+				if extraction.Property == nil || *extraction.Property == "" {
+					return nil, fmt.Errorf("step %d: variable %s needs a property", i+1, extraction.Name)
+				}
+			case AssertionSourceStatusCode:
+			default:
+				return nil, fmt.Errorf("step %d: unsupported extraction source %q", i+1, extraction.Source)
+			}
+			defined[extraction.Name] = true
+		}
+	}
+
+	return steps, nil
+}
+
+func init() {
+	// Assertions of multi-step checks belong to their steps
+	RegisterType(Type{
+		Name:       models.CheckTypeMultiStep,
+		Execute:    ExecuteMultiStepCheck,
+		Validate:   validateMultiStepCheck,
+		Thresholds: defaultThresholds,
+	})
+}
+
+// validateMultiStepCheck checks the steps of a multi-step check
+func validateMultiStepCheck(check *models.Check) error {
+	_, err := ParseSteps(check.Steps)
+	return err
+}
+
+// ExecuteMultiStepCheck runs the steps of a multi-step API check in order. The run
+// stops at the first failing step, since later steps usually depend on it, and
+// takes the worst status of the executed steps.
+func ExecuteMultiStepCheck(ctx context.Context, check *models.Check) Result {
+	started := time.Now().UTC()
+
+	steps, err := ParseSteps(check.Steps)
+	if err != nil {
+		return Result{
+			Status:           models.CheckRunStatusFailing,
+			FailureReason:    failureReasonPtr(models.FailureSerialization),
+			RequestStartedAt: started,
+			AssertionResults: emptyJSONArray(),
+			PlaywrightReport: emptyJSONObject(),
+			NetworkTimings:   emptyJSONObject(),
+			StepResults:      emptyJSONArray(),
+			Error:            err,
+		}
+	}
+
+	variables := make(map[string]string)
+	stepResults := make([]StepResult, 0, len(steps))
+	assertionResults := make([]AssertionResult, 0)
+
+	var result Result
+	status := models.CheckRunStatusPassing
+	for _, step := range steps {
+		executor := newHTTPCheckExecutor(stepCheck(check, step, variables))
+		result = executor.execute(ctx)
+
+		stepResult := StepResult{
+			Name:             step.Name,
+			Status:           result.Status,
+			FailureReason:    result.FailureReason,
+			ResponseStatus:   result.ResponseStatus,
+			RequestStartedAt: result.RequestStartedAt,
+			FirstByteAt:      result.FirstByteAt,
+			ResponseEndedAt:  result.ResponseEndedAt,
+			NetworkTimings:   result.NetworkTimings,
+			AssertionResults: result.AssertionResults,
+		}
+		if result.Error != nil {
+			stepResult.Error = result.Error.Error()
+		}
+
+		var stepAssertions []AssertionResult
+		if err := json.Unmarshal(result.AssertionResults, &stepAssertions); err == nil {
+			assertionResults = append(assertionResults, stepAssertions...)
+		}
+
+		if result.Status != models.CheckRunStatusFailing {
+			stepResult.Extracted, err = executor.extract(step.Extract, variables)
+			if err != nil {
+				stepResult.Status = models.CheckRunStatusFailing
+				stepResult.FailureReason = failureReasonPtr(models.FailureDependency)
+				stepResult.Error = err.Error()
+				result.Status = models.CheckRunStatusFailing
+				result.FailureReason = stepResult.FailureReason
+				result.Error = fmt.Errorf("step %q: %w", step.Name, err)
+			}
+		}
+
+		stepResults = append(stepResults, stepResult)
+
+		if result.Status == models.CheckRunStatusFailing {
+			status = models.CheckRunStatusFailing
+			break
+		}
+		if result.Status == models.CheckRunStatusDegraded {
+			status = models.CheckRunStatusDegraded
+		}
+	}
+
+	// The run spans all executed steps, the response is the last step's
+	result.Status = status
+	result.RequestStartedAt = stepResults[0].RequestStartedAt
+	result.FirstByteAt = stepResults[0].FirstByteAt
+	result.AssertionResults = mustMarshalJSON(assertionResults)
+	result.StepResults = mustMarshalJSON(stepResults)
+	if result.ResponseEndedAt.IsZero() {
+		result.ResponseEndedAt = time.Now().UTC()
+	}
+	result.NetworkTimings = mustMarshalJSON(map[string]interface{}{
+		"request_start":    result.RequestStartedAt.Format(time.RFC3339Nano),
+		"response_end":     result.ResponseEndedAt.Format(time.RFC3339Nano),
+		"response_time_us": durationUs(result.RequestStartedAt, result.ResponseEndedAt),
+		"steps":            len(stepResults),
+	})
+
+	return result
+}
+
+// stepCheck derives the check a step runs as, with variables substituted
+func stepCheck(check *models.Check, step Step, variables map[string]string) *models.Check {
+	derived := *check
+	derived.Method = step.Method
+	if derived.Method == "" {
+		derived.Method = http.MethodGet
+	}
+	derived.Path = substituteVariables(step.Path, variables, url.PathEscape)
+	derived.QueryParams = datatypes.JSON(substituteVariables(string(step.QueryParams), variables, escapeJSONString))
+	derived.Headers = datatypes.JSON(substituteVariables(string(step.Headers), variables, escapeJSONString))
+	derived.Body = datatypes.JSON(substituteVariables(string(step.Body), variables, escapeJSONString))
+	derived.Assertions = step.Assertions
+	// Pre- and post-scripts belong to single-request checks
+	derived.PreScript = nil
+	derived.PostScript = nil
+	return &derived
+}
+
+// substituteVariables replaces {{name}} references with their values, escaped for
+// where they are substituted so they can't break out of a path segment or the
+// surrounding JSON string.
+func substituteVariables(s string, variables map[string]string, escape func(string) string) string {
+	return variablePattern.ReplaceAllStringFunc(s, func(match string) string {
+		name := variablePattern.FindStringSubmatch(match)[1]
+		value, ok := variables[name]
+		if !ok {
+			return match
+		}
+		return escape(value)
+	})
+}
+
+// escapeJSONString escapes a value for use inside a JSON string
+func escapeJSONString(value string) string {
+	encoded, _ := json.Marshal(value)
+	return strings.Trim(string(encoded), `"`)
+}
+
+// extract stores the step's extracted values in variables and returns them
+func (e *httpCheckExecutor) extract(extractions []Extraction, variables map[string]string) (map[string]interface{}, error) {
+	if len(extractions) == 0 {
+		return nil, nil
+	}
+
+	extracted := make(map[string]interface{}, len(extractions))
+	for _, extraction := range extractions {
+		var value interface{}
+		var err error
+
+		switch extraction.Source {
+		case AssertionSourceStatusCode:
+			value = e.statusCode
+		case AssertionSourceResponseHeaders:
+			value, err = resolvePath(headersToMap(http.Header(e.responseHeaders)), extraction.Property)
+		case AssertionSourceResponseBodyJSON:
+			var body interface{}
+			if err = json.Unmarshal(e.responseBody, &body); err == nil {
+				value, err = resolvePath(body, extraction.Property)
+			}
+		default:
+			err = fmt.Errorf("unsupported extraction source %q", extraction.Source)
+		}
+		if err != nil {
+			return extracted, fmt.Errorf("extracting %s: %w", extraction.Name, err)
+		}
+
+		extracted[extraction.Name] = value
+		if str, ok := value.(string); ok {
+			variables[extraction.Name] = str
+		} else {
+			encoded, _ := json.Marshal(value)
+			variables[extraction.Name] = string(encoded)
+		}
+	}
+
+	return extracted, nil
+}
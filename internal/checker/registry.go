@@ -0,0 +1,168 @@
+package checker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"pulse/internal/models"
+)
+
+var (
+	// ErrUnknownCheckType is returned for checks of a type that isn't registered.
+	ErrUnknownCheckType = errors.New("unknown check type")
+	// ErrHostRequired is returned for probed checks without a host.
+	ErrHostRequired = errors.New("host is required")
+)
+
+// Executor runs a single attempt of a check. Retries, the run deadline and
+// should_fail are applied around it by Execute.
+type Executor func(ctx context.Context, check *models.Check) Result
+
+// Thresholds are the response time thresholds a check defaults to
+type Thresholds struct {
+	Degraded     int
+	DegradedUnit models.UnitType
+	Failed       int
+	FailedUnit   models.UnitType
+}
+
+// defaultThresholds are the thresholds of types without their own
+var defaultThresholds = Thresholds{
+	Degraded:     3000,
+	DegradedUnit: models.UnitTypeMs,
+	Failed:       5000,
+	FailedUnit:   models.UnitTypeMs,
+}
+
+// Type describes a type of check: how it is run, how its configuration is
+// validated and what it defaults to. Each type registers itself with RegisterType.
+type Type struct {
+	Name models.CheckType
+
+	// Execute runs the check. Types without an executor can be configured but
+	// their runs fail as unsupported.
+	Execute Executor
+
+	// Validate checks the type-specific configuration of a check, after the host
+	// and assertions were validated. It may be nil.
+	Validate func(check *models.Check) error
+
+	// Thresholds are applied to checks created without thresholds
+	Thresholds Thresholds
+
+	// AssertionSources lists the assertion sources the type's runs can evaluate.
+	// Checks of types without any can't have assertions.
+	AssertionSources []AssertionSource
+
+	// Passive types aren't probed but evaluated from what the monitored system
+	// reports, so they have no host and can't be test run.
+	Passive bool
+}
+
+var (
+	typesMu sync.RWMutex
+	types   = make(map[models.CheckType]*Type)
+)
+
+// RegisterType makes a type of check available. It panics if the type is already
+// registered, so it is meant to be called from init functions.
+func RegisterType(t Type) {
+	typesMu.Lock()
+	defer typesMu.Unlock()
+
+	if _, ok := types[t.Name]; ok {
+		panic(fmt.Sprintf("checker: type %s registered twice", t.Name))
+	}
+	types[t.Name] = &t
+}
+
+// LookupType returns the registered type of the given name
+func LookupType(name models.CheckType) (*Type, bool) {
+	typesMu.RLock()
+	defer typesMu.RUnlock()
+
+	t, ok := types[name]
+	return t, ok
+}
+
+// Types returns the names of all registered types, sorted
+func Types() []models.CheckType {
+	typesMu.RLock()
+	defer typesMu.RUnlock()
+
+	names := make([]models.CheckType, 0, len(types))
+	for name := range types {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
+
+// SupportsAssertionSource reports whether runs of the type can evaluate
+// assertions on the given source.
+func (t *Type) SupportsAssertionSource(source AssertionSource) bool {
+	for _, s := range t.AssertionSources {
+		if s == source {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateCheck checks a check against its registered type: the type must exist,
+// probed checks need a host, assertions must compile and read sources the type
+// supports, and the type's own validation must pass.
+func ValidateCheck(check *models.Check) error {
+	t, ok := LookupType(check.Type)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownCheckType, check.Type)
+	}
+
+	if check.Host == "" && !t.Passive {
+		return ErrHostRequired
+	}
+
+	if err := ValidateAssertions(check.Assertions); err != nil {
+		return err
+	}
+	if err := t.validateAssertionSources(check); err != nil {
+		return err
+	}
+
+	if t.Validate != nil {
+		return t.Validate(check)
+	}
+	return nil
+}
+
+// validateAssertionSources rejects assertions the type's runs can't evaluate
+func (t *Type) validateAssertionSources(check *models.Check) error {
+	if len(check.Assertions) == 0 {
+		return nil
+	}
+
+	var assertions []Assertion
+	if err := json.Unmarshal(check.Assertions, &assertions); err != nil {
+		return fmt.Errorf("invalid assertions: %w", err)
+	}
+
+	for i, a := range assertions {
+		if !t.SupportsAssertionSource(a.Source) {
+			return fmt.Errorf("assertion %d: %s is not supported by %s checks", i+1, a.Source, t.Name)
+		}
+	}
+	return nil
+}
+
+// executeType runs a single attempt of a check with the executor of its type
+func executeType(ctx context.Context, check *models.Check) Result {
+	t, ok := LookupType(check.Type)
+	if !ok || t.Execute == nil {
+		return unsupportedResult(check)
+	}
+	return t.Execute(ctx, check)
+}
@@ -19,12 +19,16 @@ const (
 type ResponseBuilder struct{}
 
 // BuildHTTPResponse builds a uniform HTTP response structure.
-func (rb *ResponseBuilder) BuildHTTPResponse(headers map[string][]string, body []byte, contentType, proto string) datatypes.JSON {
+// tlsInfo is nil for plain HTTP.
+func (rb *ResponseBuilder) BuildHTTPResponse(headers map[string][]string, body []byte, contentType, proto string, tlsInfo *TLSInfo) datatypes.JSON {
 	response := make(map[string]interface{})
 	response["type"] = "http"
 	response["headers"] = headers
 	response["content_type"] = contentType
 	response["proto"] = proto
+	if tlsInfo != nil {
+		response["tls"] = tlsInfo
+	}
 
 	bodySize := len(body)
 	response["body_size_bytes"] = bodySize
@@ -62,10 +66,14 @@ func (rb *ResponseBuilder) BuildHTTPResponse(headers map[string][]string, body [
 }
 
 // BuildTCPResponse builds a uniform TCP response structure.
-func (rb *ResponseBuilder) BuildTCPResponse() datatypes.JSON {
+// tlsInfo is nil for plain TCP connections.
+func (rb *ResponseBuilder) BuildTCPResponse(tlsInfo *TLSInfo) datatypes.JSON {
 	response := make(map[string]interface{})
 	response["type"] = "tcp"
 	response["connection_status"] = "established"
+	if tlsInfo != nil {
+		response["tls"] = tlsInfo
+	}
 
 	return mustMarshalJSON(response)
 }
@@ -30,6 +30,11 @@ type Result struct {
 	PlaywrightReport datatypes.JSON
 	NetworkTimings   datatypes.JSON
 	Response         datatypes.JSON
+	StepResults      datatypes.JSON // per-step outcome of multi-step checks
+	ScriptLogs       datatypes.JSON // console output and errors of pre- and post-scripts
+
+	// Inverted is set when the check's ShouldFail flag flipped the verdict
+	Inverted bool
 
 	Error error
 }
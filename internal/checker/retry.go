@@ -10,37 +10,29 @@ import (
 	"pulse/internal/models"
 )
 
-func executeOnce(check *models.Check) Result {
-	switch check.Type {
-	case models.CheckTypeHTTP:
-		return ExecuteHTTPCheck(context.Background(), check)
-	case models.CheckTypeTCP:
-		return ExecuteTCPCheck(context.Background(), check)
-	case models.CheckTypeDNS:
-		return ExecuteDNSCheck(context.Background(), check)
-	// case models.CheckTypeBrowser:
-	// 	return executeBrowserCheck(check)
-	// case models.CheckTypeHeartbeat:
-	// 	return executeHeartbeatCheck(check)
-	default:
-		now := time.Now().UTC()
-		err := fmt.Errorf("unsupported check type: %s", check.Type)
-		return Result{
-			Status:            models.CheckRunStatusFailing,
-			FailureReason:     failureReasonPtr(models.FailureUnknown),
-			ResponseStatus:    nil,
-			RequestStartedAt:  now,
-			FirstByteAt:       time.Time{},
-			ResponseEndedAt:   time.Time{},
-			ConnectionReused:  false,
-			IPVersion:         "",
-			IPAddress:         "",
-			ResponseSizeBytes: 0,
-			AssertionResults:  emptyJSONArray(),
-			PlaywrightReport:  emptyJSONObject(),
-			NetworkTimings:    emptyJSONObject(),
-			Error:             err,
-		}
+func executeOnce(ctx context.Context, check *models.Check) Result {
+	return applyShouldFail(check, executeType(ctx, check))
+}
+
+// unsupportedResult is the result of checks whose type can't be run
+func unsupportedResult(check *models.Check) Result {
+	now := time.Now().UTC()
+	err := fmt.Errorf("unsupported check type: %s", check.Type)
+	return Result{
+		Status:            models.CheckRunStatusFailing,
+		FailureReason:     failureReasonPtr(models.FailureUnknown),
+		ResponseStatus:    nil,
+		RequestStartedAt:  now,
+		FirstByteAt:       time.Time{},
+		ResponseEndedAt:   time.Time{},
+		ConnectionReused:  false,
+		IPVersion:         "",
+		IPAddress:         "",
+		ResponseSizeBytes: 0,
+		AssertionResults:  emptyJSONArray(),
+		PlaywrightReport:  emptyJSONObject(),
+		NetworkTimings:    emptyJSONObject(),
+		Error:             err,
 	}
 }
 
@@ -0,0 +1,381 @@
+package checker
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime/metrics"
+	"strings"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+
+	"pulse/internal/models"
+)
+
+const (
+	// scriptTimeout bounds the wall-clock (and so CPU) time of a single script
+	scriptTimeout = 5 * time.Second
+	// scriptMemoryLimit bounds how much the live heap may grow while a script runs.
+	// goja can't account for a runtime's allocations, so the heap retained after
+	// each GC is sampled instead. Garbage doesn't count towards it, but memory
+	// retained by other goroutines meanwhile does, so the limit is generous.
+	scriptMemoryLimit = 256 << 20
+	// scriptMemoryInterval is how often the live heap is sampled
+	scriptMemoryInterval = 10 * time.Millisecond
+	// scriptMaxCallStack bounds recursion depth
+	scriptMaxCallStack = 1024
+	// scriptMaxSourceSize bounds the size of a script's source
+	scriptMaxSourceSize = 64 * 1024
+	// scriptMaxLogs and scriptMaxLogLength bound the captured console output
+	scriptMaxLogs      = 100
+	scriptMaxLogLength = 2048
+
+	// AssertionSourceScript marks assertion results added by a post-script
+	AssertionSourceScript AssertionSource = "script"
+)
+
+var (
+	// ErrScriptTimeout is returned when a script exceeds its time limit.
+	ErrScriptTimeout = errors.New("script timed out")
+	// ErrScriptMemory is returned when a script exceeds its memory limit.
+	ErrScriptMemory = errors.New("script exceeded memory limit")
+	// ErrScriptTooLarge is returned when a script's source is too large.
+	ErrScriptTooLarge = errors.New("script is too large")
+)
+
+// ScriptLog is a line of console output or an error of a pre- or post-script.
+type ScriptLog struct {
+	Script  string    `json:"script"` // "pre" or "post"
+	Level   string    `json:"level"`  // log, info, warn, error
+	Message string    `json:"message"`
+	Time    time.Time `json:"time"`
+}
+
+// ScriptRequest is the outgoing request exposed to a pre-script as `request`.
+// The script may change any field before the request is sent.
+type ScriptRequest struct {
+	Method      string                 `json:"method"`
+	Path        string                 `json:"path"`
+	Headers     map[string]interface{} `json:"headers"`
+	QueryParams map[string]interface{} `json:"query_params"`
+	Body        string                 `json:"body"`
+}
+
+// ScriptResponse is the received response exposed to a post-script as `response`.
+type ScriptResponse struct {
+	StatusCode int                    `json:"status_code"`
+	Headers    map[string]interface{} `json:"headers"`
+	Body       string                 `json:"body"`
+	JSON       interface{}            `json:"json"`
+	TimeMs     int64                  `json:"time_ms"`
+}
+
+// scriptRunner runs the scripts of one check execution and collects their output
+type scriptRunner struct {
+	logs []ScriptLog
+}
+
+// ValidateScript compiles a script without running it.
+func ValidateScript(source string) error {
+	if len(source) > scriptMaxSourceSize {
+		return ErrScriptTooLarge
+	}
+	_, err := goja.Compile("script.js", source, true)
+	return err
+}
+
+// runPreScript runs the check's pre-script against the request it is about to send
+// and returns the check to execute, with the script's changes applied.
+func (r *scriptRunner) runPreScript(ctx context.Context, check *models.Check) (*models.Check, error) {
+	request := ScriptRequest{
+		Method:      check.Method,
+		Path:        check.Path,
+		Headers:     map[string]interface{}{},
+		QueryParams: map[string]interface{}{},
+		Body:        string(check.Body),
+	}
+	if len(check.Headers) > 0 {
+		_ = json.Unmarshal(check.Headers, &request.Headers)
+	}
+	if len(check.QueryParams) > 0 {
+		_ = json.Unmarshal(check.QueryParams, &request.QueryParams)
+	}
+
+	vm, err := r.newVM("pre")
+	if err != nil {
+		return nil, err
+	}
+
+	// Round-trip through JSON so the script sees plain JS objects it can mutate
+	requestObject, err := toJSValue(vm, request)
+	if err != nil {
+		return nil, err
+	}
+	if err := vm.Set("request", requestObject); err != nil {
+		return nil, err
+	}
+
+	if err := r.run(ctx, vm, "pre", *check.PreScript); err != nil {
+		return nil, err
+	}
+
+	if err := fromJSValue(vm.Get("request"), &request); err != nil {
+		return nil, fmt.Errorf("invalid request after pre-script: %w", err)
+	}
+
+	derived := *check
+	derived.Method = request.Method
+	derived.Path = request.Path
+	derived.Headers = mustMarshalJSON(request.Headers)
+	derived.QueryParams = mustMarshalJSON(request.QueryParams)
+	derived.Body = datatypes.JSON(request.Body)
+	return &derived, nil
+}
+
+// runPostScript runs the check's post-script against the response and returns the
+// assertion results it added with assert(name, passed, received).
+func (r *scriptRunner) runPostScript(ctx context.Context, check *models.Check, response ScriptResponse) ([]AssertionResult, error) {
+	if json.Valid([]byte(response.Body)) {
+		_ = json.Unmarshal([]byte(response.Body), &response.JSON)
+	}
+
+	vm, err := r.newVM("post")
+	if err != nil {
+		return nil, err
+	}
+
+	responseObject, err := toJSValue(vm, response)
+	if err != nil {
+		return nil, err
+	}
+	if err := vm.Set("response", responseObject); err != nil {
+		return nil, err
+	}
+
+	results := make([]AssertionResult, 0)
+	assert := func(name string, passed bool, received goja.Value) {
+		property := name
+		result := AssertionResult{
+			Assertion: Assertion{
+				Source:     AssertionSourceScript,
+				Property:   &property,
+				Comparison: "script",
+			},
+			Passed: passed,
+		}
+		if received != nil && !goja.IsUndefined(received) {
+			result.Received = received.Export()
+		}
+		results = append(results, result)
+	}
+	if err := vm.Set("assert", assert); err != nil {
+		return nil, err
+	}
+
+	if err := r.run(ctx, vm, "post", *check.PostScript); err != nil {
+		return results, err
+	}
+
+	return results, nil
+}
+
+// newVM creates a runtime with the console and helper globals
+func (r *scriptRunner) newVM(script string) (*goja.Runtime, error) {
+	vm := goja.New()
+	vm.SetFieldNameMapper(goja.TagFieldNameMapper("json", true))
+	vm.SetMaxCallStackSize(scriptMaxCallStack)
+
+	console := vm.NewObject()
+	for _, level := range []string{"log", "info", "warn", "error"} {
+		level := level
+		if err := console.Set(level, func(call goja.FunctionCall) goja.Value {
+			parts := make([]string, 0, len(call.Arguments))
+			for _, arg := range call.Arguments {
+				parts = append(parts, formatJSValue(arg))
+			}
+			r.log(script, level, strings.Join(parts, " "))
+			return goja.Undefined()
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if err := vm.Set("console", console); err != nil {
+		return nil, err
+	}
+
+	// Helpers for computing request signatures and tokens
+	pulse := vm.NewObject()
+	helpers := map[string]interface{}{
+		"hmacSHA256": func(key, message string) string {
+			mac := hmac.New(sha256.New, []byte(key))
+			mac.Write([]byte(message))
+			return hex.EncodeToString(mac.Sum(nil))
+		},
+		"sha256": func(message string) string {
+			sum := sha256.Sum256([]byte(message))
+			return hex.EncodeToString(sum[:])
+		},
+		"base64Encode": func(message string) string {
+			return base64.StdEncoding.EncodeToString([]byte(message))
+		},
+		"base64Decode": func(encoded string) (string, error) {
+			decoded, err := base64.StdEncoding.DecodeString(encoded)
+			return string(decoded), err
+		},
+		"uuid": func() string {
+			return uuid.NewString()
+		},
+	}
+	for name, fn := range helpers {
+		if err := pulse.Set(name, fn); err != nil {
+			return nil, err
+		}
+	}
+	if err := vm.Set("pulse", pulse); err != nil {
+		return nil, err
+	}
+
+	return vm, nil
+}
+
+// run executes a script, interrupting it when it exceeds its time or memory limit
+// or when ctx is cancelled. Errors are also recorded in the script's logs.
+func (r *scriptRunner) run(ctx context.Context, vm *goja.Runtime, script, source string) error {
+	err := r.execute(ctx, vm, source)
+	if err != nil {
+		r.log(script, "error", err.Error())
+		return fmt.Errorf("%s-script: %w", script, err)
+	}
+	return nil
+}
+
+func (r *scriptRunner) execute(ctx context.Context, vm *goja.Runtime, source string) error {
+	if len(source) > scriptMaxSourceSize {
+		return ErrScriptTooLarge
+	}
+
+	program, err := goja.Compile("script.js", source, true)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, scriptTimeout)
+	defer cancel()
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		ticker := time.NewTicker(scriptMemoryInterval)
+		defer ticker.Stop()
+
+		baseline := liveHeap()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				vm.Interrupt(ErrScriptTimeout)
+				return
+			case <-ticker.C:
+				if live := liveHeap(); live > baseline && live-baseline > scriptMemoryLimit {
+					vm.Interrupt(ErrScriptMemory)
+					return
+				}
+			}
+		}
+	}()
+
+	_, err = vm.RunProgram(program)
+	if err != nil {
+		var interrupted *goja.InterruptedError
+		if errors.As(err, &interrupted) {
+			if cause, ok := interrupted.Value().(error); ok {
+				return cause
+			}
+		}
+		return err
+	}
+
+	return nil
+}
+
+// log records a line of console output, dropping lines past the limit
+func (r *scriptRunner) log(script, level, message string) {
+	if len(r.logs) >= scriptMaxLogs {
+		return
+	}
+	if len(message) > scriptMaxLogLength {
+		message = message[:scriptMaxLogLength] + "…"
+	}
+	r.logs = append(r.logs, ScriptLog{
+		Script:  script,
+		Level:   level,
+		Message: message,
+		Time:    time.Now().UTC(),
+	})
+}
+
+// logsJSON returns the captured output for the run, or nil if no script ran
+func (r *scriptRunner) logsJSON() datatypes.JSON {
+	if r == nil || len(r.logs) == 0 {
+		return nil
+	}
+	return mustMarshalJSON(r.logs)
+}
+
+// liveHeap returns the bytes of heap objects the last GC found reachable
+func liveHeap() uint64 {
+	sample := []metrics.Sample{{Name: "/gc/heap/live:bytes"}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return sample[0].Value.Uint64()
+}
+
+// toJSValue converts v to a plain JS value through JSON
+func toJSValue(vm *goja.Runtime, v interface{}) (goja.Value, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var plain interface{}
+	if err := json.Unmarshal(data, &plain); err != nil {
+		return nil, err
+	}
+	return vm.ToValue(plain), nil
+}
+
+// fromJSValue converts a JS value back into v through JSON
+func fromJSValue(value goja.Value, v interface{}) error {
+	data, err := json.Marshal(value.Export())
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// formatJSValue renders a console argument, objects as JSON
+func formatJSValue(value goja.Value) string {
+	if value == nil || goja.IsUndefined(value) {
+		return "undefined"
+	}
+	if _, ok := value.Export().(string); ok {
+		return value.String()
+	}
+	if obj, ok := value.(*goja.Object); ok {
+		if data, err := json.Marshal(obj.Export()); err == nil {
+			return string(data)
+		}
+	}
+	return value.String()
+}
@@ -0,0 +1,161 @@
+package checker
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
+	"github.com/antchfx/xmlquery"
+	"github.com/antchfx/xpath"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// schemaURL is the location inline schemas are compiled under
+const schemaURL = "assertion-schema.json"
+
+// compileSchema compiles an inline JSON Schema. Remote and file references are not
+// loaded, so a schema can only reference itself and the standard meta-schemas.
+func compileSchema(schema string) (*jsonschema.Schema, error) {
+	doc, err := jsonschema.UnmarshalJSON(strings.NewReader(schema))
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %w", err)
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.UseLoader(jsonschema.SchemeURLLoader{})
+	if err := compiler.AddResource(schemaURL, doc); err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %w", err)
+	}
+	return compiler.Compile(schemaURL)
+}
+
+// validateJSONSchema validates a JSON document against an inline JSON Schema and
+// returns the validation errors, or nil if the document is valid.
+func validateJSONSchema(body, schema string) ([]string, error) {
+	compiled, err := compileSchema(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	instance, err := jsonschema.UnmarshalJSON(strings.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	if err := compiled.Validate(instance); err != nil {
+		validationErr, ok := err.(*jsonschema.ValidationError)
+		if !ok {
+			return nil, err
+		}
+		return schemaErrors(validationErr), nil
+	}
+	return nil, nil
+}
+
+// schemaErrors flattens a validation error into one message per failing location
+func schemaErrors(err *jsonschema.ValidationError) []string {
+	output := err.BasicOutput()
+	messages := make([]string, 0, len(output.Errors))
+	for _, unit := range output.Errors {
+		if unit.Error == nil {
+			continue
+		}
+		location := unit.InstanceLocation
+		if location == "" {
+			location = "/"
+		}
+		messages = append(messages, fmt.Sprintf("%s: %s", location, unit.Error.String()))
+	}
+	if len(messages) == 0 {
+		messages = append(messages, err.Error())
+	}
+	return messages
+}
+
+// selectHTML returns the text of the elements of an HTML document matching a CSS
+// selector: a string for a single match, a list for several.
+func selectHTML(body, selector string) (interface{}, error) {
+	compiled, err := cascadia.Compile(selector)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPath, err)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	selection := doc.FindMatcher(compiled)
+	if selection.Length() == 0 {
+		return nil, fmt.Errorf("%w: no element matches %s", ErrPathNotFound, selector)
+	}
+
+	texts := make([]interface{}, 0, selection.Length())
+	selection.Each(func(_ int, s *goquery.Selection) {
+		texts = append(texts, strings.TrimSpace(s.Text()))
+	})
+	if len(texts) == 1 {
+		return texts[0], nil
+	}
+	return texts, nil
+}
+
+// evaluateXPath evaluates an XPath expression against an XML document. Node sets
+// yield the text of the nodes, a string for a single node and a list for several;
+// other expressions such as count() yield their number, string or boolean.
+func evaluateXPath(body, expression string) (interface{}, error) {
+	compiled, err := xpath.Compile(expression)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPath, err)
+	}
+
+	doc, err := xmlquery.Parse(strings.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse XML: %w", err)
+	}
+
+	switch value := compiled.Evaluate(xmlquery.CreateXPathNavigator(doc)).(type) {
+	case *xpath.NodeIterator:
+		texts := make([]interface{}, 0)
+		for value.MoveNext() {
+			texts = append(texts, strings.TrimSpace(value.Current().Value()))
+		}
+		switch len(texts) {
+		case 0:
+			return nil, fmt.Errorf("%w: no node matches %s", ErrPathNotFound, expression)
+		case 1:
+			return texts[0], nil
+		default:
+			return texts, nil
+		}
+	default:
+		return value, nil
+	}
+}
+
+// validateSelector checks that the CSS selector or XPath expression of an HTML or
+// XML assertion compiles.
+func validateSelector(source AssertionSource, property string) error {
+	var err error
+	if source == AssertionSourceResponseBodyHTML {
+		_, err = cascadia.Compile(property)
+	} else {
+		_, err = xpath.Compile(property)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPath, err)
+	}
+	return nil
+}
+
+// matchesRegex reports whether actual matches the expected pattern. Invalid
+// patterns never match.
+func matchesRegex(actual, pattern string) bool {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return false
+	}
+	return re.MatchString(actual)
+}
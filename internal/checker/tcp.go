@@ -2,6 +2,7 @@ package checker
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net"
 	"time"
@@ -16,6 +17,7 @@ type tcpCheckExecutor struct {
 	ipVersion        string
 	ipAddress        string
 	connectionReused bool
+	tlsInfo          *TLSInfo
 }
 
 // tcpTimingTracker tracks TCP connection timing events.
@@ -25,11 +27,34 @@ type tcpTimingTracker struct {
 	dnsDone      time.Time
 	connectStart time.Time
 	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
 	responseEnd  time.Time
 }
 
+func init() {
+	RegisterType(Type{
+		Name:    models.CheckTypeTCP,
+		Execute: ExecuteTCPCheck,
+		Thresholds: Thresholds{
+			Degraded:     1000,
+			DegradedUnit: models.UnitTypeMs,
+			Failed:       3000,
+			FailedUnit:   models.UnitTypeMs,
+		},
+		// Connection assertions, the TLS ones only for secure checks
+		AssertionSources: []AssertionSource{
+			AssertionSourceResponseTimeMs,
+			AssertionSourceSSLDaysRemaining,
+			AssertionSourceTLSVersion,
+			AssertionSourceCertificateIssuer,
+		},
+	})
+}
+
 // ExecuteTCPCheck performs a TCP check and returns the result.
-// It handles DNS resolution, connection establishment, and timing tracking.
+// It handles DNS resolution, connection establishment, the TLS handshake of
+// secure checks, and timing tracking.
 func ExecuteTCPCheck(ctx context.Context, check *models.Check) Result {
 	executor := newTCPCheckExecutor(check)
 	return executor.execute(ctx)
@@ -62,6 +87,13 @@ func (e *tcpCheckExecutor) execute(ctx context.Context) Result {
 	}
 	defer conn.Close()
 
+	// Negotiate TLS on top of the connection for secure checks
+	if e.check.Secure {
+		if err := e.handshake(ctx, conn); err != nil {
+			return e.createErrorResult(err)
+		}
+	}
+
 	// Connection successful - record end time
 	e.timings.responseEnd = time.Now().UTC()
 
@@ -170,13 +202,8 @@ func (e *tcpCheckExecutor) connect(ctx context.Context, address string) (net.Con
 	}
 
 	// Create dialer with timeout
-	timeout := defaultTimeout
-	if e.check.FailedThresholdDuration() > 0 {
-		timeout = e.check.FailedThresholdDuration()
-	}
-
 	dialer := &net.Dialer{
-		Timeout: timeout,
+		Timeout: e.timeout(),
 	}
 
 	// Determine network type based on IP version (strict enforcement)
@@ -198,6 +225,40 @@ func (e *tcpCheckExecutor) connect(ctx context.Context, address string) (net.Con
 	return conn, nil
 }
 
+// handshake performs a TLS handshake over the connection and captures the
+// negotiated parameters and peer certificate chain.
+func (e *tcpCheckExecutor) handshake(ctx context.Context, conn net.Conn) error {
+	e.timings.tlsStart = time.Now().UTC()
+
+	tlsConn := tls.Client(conn, &tls.Config{
+		ServerName:         e.check.Host,
+		InsecureSkipVerify: e.check.SkipSSLVerification,
+		MinVersion:         tls.VersionTLS12,
+	})
+	if err := conn.SetDeadline(time.Now().Add(e.timeout())); err != nil {
+		e.timings.tlsDone = time.Now().UTC()
+		return err
+	}
+
+	err := tlsConn.HandshakeContext(ctx)
+	e.timings.tlsDone = time.Now().UTC()
+	if err != nil {
+		return fmt.Errorf("tls handshake failed: %w", err)
+	}
+
+	state := tlsConn.ConnectionState()
+	e.tlsInfo = buildTLSInfo(&state, e.timings.tlsDone)
+	return nil
+}
+
+// timeout returns the connection timeout, bounded by the failed threshold.
+func (e *tcpCheckExecutor) timeout() time.Duration {
+	if e.check.FailedThresholdDuration() > 0 {
+		return e.check.FailedThresholdDuration()
+	}
+	return defaultTimeout
+}
+
 // extractIPInfo extracts IP version and address from the connection.
 func (e *tcpCheckExecutor) extractIPInfo(conn net.Conn) {
 	if conn == nil {
@@ -245,6 +306,12 @@ func (e *tcpCheckExecutor) buildNetworkTimings() map[string]interface{} {
 	if !e.timings.connectDone.IsZero() {
 		timings["tcp_done"] = e.timings.connectDone.Format(time.RFC3339Nano)
 	}
+	if !e.timings.tlsStart.IsZero() {
+		timings["tls_start"] = e.timings.tlsStart.Format(time.RFC3339Nano)
+	}
+	if !e.timings.tlsDone.IsZero() {
+		timings["tls_done"] = e.timings.tlsDone.Format(time.RFC3339Nano)
+	}
 	if !e.timings.responseEnd.IsZero() {
 		timings["response_end"] = e.timings.responseEnd.Format(time.RFC3339Nano)
 	}
@@ -262,6 +329,16 @@ func (e *tcpCheckExecutor) buildNetworkTimings() map[string]interface{} {
 			timings["tcp_duration_us"] = us
 		}
 	}
+	// TLS handshake duration: tls_done - tls_start
+	if !e.timings.tlsStart.IsZero() && !e.timings.tlsDone.IsZero() && e.timings.tlsDone.After(e.timings.tlsStart) {
+		if us := durationUs(e.timings.tlsStart, e.timings.tlsDone); us > 0 {
+			timings["tls_duration_us"] = us
+		}
+	}
+	if e.tlsInfo != nil {
+		timings["tls_version"] = e.tlsInfo.Version
+		timings["tls_cipher_suite"] = e.tlsInfo.CipherSuite
+	}
 	// Total connection time: response_end - request_start
 	if !e.timings.requestStart.IsZero() && !e.timings.responseEnd.IsZero() && e.timings.responseEnd.After(e.timings.requestStart) {
 		responseTime := e.responseTime()
@@ -281,8 +358,15 @@ func (e *tcpCheckExecutor) responseTime() time.Duration {
 	return e.timings.responseEnd.Sub(e.timings.requestStart)
 }
 
-// determineStatus calculates the check status based on response time and thresholds.
-func (e *tcpCheckExecutor) determineStatus(responseTime time.Duration) models.CheckRunStatus {
+// determineStatus calculates the check status based on response time, assertions and thresholds.
+func (e *tcpCheckExecutor) determineStatus(responseTime time.Duration, assertionResults []AssertionResult) models.CheckRunStatus {
+	// Check if any assertions failed
+	for _, result := range assertionResults {
+		if !result.Passed {
+			return models.CheckRunStatusFailing
+		}
+	}
+
 	// Check response time thresholds
 	if responseTime > e.check.FailedThresholdDuration() {
 		return models.CheckRunStatusFailing
@@ -302,13 +386,23 @@ func (e *tcpCheckExecutor) buildResult() Result {
 	// Build network timings
 	networkTimings := e.buildNetworkTimings()
 
+	// Evaluate assertions against the connection
+	assertionResults := []AssertionResult{}
+	if len(e.check.Assertions) > 0 {
+		results, err := ProcessConnectionAssertions(e.check.Assertions, e.tlsInfo, responseTime)
+		if err != nil {
+			return e.createErrorResult(fmt.Errorf("%w: %v", ErrAssertionProcessing, err))
+		}
+		assertionResults = results
+	}
+
 	// Determine status
-	status := e.determineStatus(responseTime)
+	status := e.determineStatus(responseTime, assertionResults)
 
 	// Determine failure reason if failed
 	var failureReason *models.FailureReason
 	if status == models.CheckRunStatusFailing {
-		failureReason = e.determineFailureReason(responseTime)
+		failureReason = e.determineFailureReason(responseTime, assertionResults)
 	}
 
 	// Validate timeline invariants
@@ -320,10 +414,13 @@ func (e *tcpCheckExecutor) buildResult() Result {
 		}
 	}
 
+	// Escalate when the certificate chain is about to expire
+	status, failureReason = applyCertificateExpiry(e.check, e.tlsInfo, status, failureReason)
+
 	// Build response data using unified builder
 	// Note: IP info and connection_reused are already in CheckRun fields, not duplicated here
 	rb := &ResponseBuilder{}
-	responseData := rb.BuildTCPResponse()
+	responseData := rb.BuildTCPResponse(e.tlsInfo)
 
 	return Result{
 		Status:            status,
@@ -335,8 +432,8 @@ func (e *tcpCheckExecutor) buildResult() Result {
 		ConnectionReused:  e.connectionReused,
 		IPVersion:         e.ipVersion,
 		IPAddress:         e.ipAddress,
-		ResponseSizeBytes: 0,                 // TCP connection check doesn't transfer data
-		AssertionResults:  emptyJSONObject(), // TCP checks don't have assertions yet
+		ResponseSizeBytes: 0, // TCP connection check doesn't transfer data
+		AssertionResults:  mustMarshalJSON(assertionResults),
 		PlaywrightReport:  emptyJSONObject(),
 		NetworkTimings:    mustMarshalJSON(networkTimings),
 		Response:          responseData,
@@ -386,6 +483,9 @@ func (e *tcpCheckExecutor) classifyError(err error) *models.FailureReason {
 	if contains(errStr, "ip version mismatch") {
 		return failureReasonPtr(models.FailureIPVersionMismatch)
 	}
+	if contains(errStr, "tls") || contains(errStr, "certificate") || contains(errStr, "x509") {
+		return failureReasonPtr(models.FailureTLS)
+	}
 	if contains(errStr, "no such host") || contains(errStr, "dns") {
 		return failureReasonPtr(models.FailureDNS)
 	}
@@ -408,8 +508,15 @@ func (e *tcpCheckExecutor) classifyError(err error) *models.FailureReason {
 	return failureReasonPtr(models.FailureUnknown)
 }
 
-// determineFailureReason determines the failure reason based on response time.
-func (e *tcpCheckExecutor) determineFailureReason(responseTime time.Duration) *models.FailureReason {
+// determineFailureReason determines the failure reason based on assertions and response time.
+func (e *tcpCheckExecutor) determineFailureReason(responseTime time.Duration, assertionResults []AssertionResult) *models.FailureReason {
+	// Check assertions first
+	for _, result := range assertionResults {
+		if !result.Passed {
+			return failureReasonPtr(models.FailureAssertionFailed)
+		}
+	}
+
 	// Check timeouts
 	failedThreshold := e.check.FailedThresholdDuration()
 	if failedThreshold > 0 && responseTime > failedThreshold {
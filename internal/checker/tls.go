@@ -0,0 +1,126 @@
+package checker
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"time"
+
+	"pulse/internal/models"
+)
+
+// CertificateInfo describes a certificate presented by the server.
+type CertificateInfo struct {
+	Subject            string    `json:"subject"`
+	CommonName         string    `json:"common_name"`
+	DNSNames           []string  `json:"dns_names,omitempty"`
+	IPAddresses        []string  `json:"ip_addresses,omitempty"`
+	Issuer             string    `json:"issuer"`
+	IssuerCommonName   string    `json:"issuer_common_name"`
+	SerialNumber       string    `json:"serial_number"`
+	NotBefore          time.Time `json:"not_before"`
+	NotAfter           time.Time `json:"not_after"`
+	DaysRemaining      int       `json:"days_remaining"`
+	KeyType            string    `json:"key_type"`
+	SignatureAlgorithm string    `json:"signature_algorithm"`
+	FingerprintSHA256  string    `json:"fingerprint_sha256"`
+}
+
+// TLSInfo describes a negotiated TLS connection and the peer certificate chain.
+type TLSInfo struct {
+	Version     string            `json:"version"`
+	CipherSuite string            `json:"cipher_suite"`
+	ServerName  string            `json:"server_name,omitempty"`
+	Chain       []CertificateInfo `json:"chain"`
+	// DaysRemaining is the time until the first certificate of the chain expires
+	DaysRemaining int `json:"days_remaining"`
+}
+
+// buildTLSInfo captures the negotiated parameters and certificate chain of a connection.
+func buildTLSInfo(state *tls.ConnectionState, now time.Time) *TLSInfo {
+	if state == nil {
+		return nil
+	}
+
+	info := &TLSInfo{
+		Version:       tls.VersionName(state.Version),
+		CipherSuite:   tls.CipherSuiteName(state.CipherSuite),
+		ServerName:    state.ServerName,
+		Chain:         make([]CertificateInfo, 0, len(state.PeerCertificates)),
+		DaysRemaining: math.MaxInt32,
+	}
+
+	for _, cert := range state.PeerCertificates {
+		certInfo := buildCertificateInfo(cert, now)
+		info.Chain = append(info.Chain, certInfo)
+		info.DaysRemaining = min(info.DaysRemaining, certInfo.DaysRemaining)
+	}
+	if len(info.Chain) == 0 {
+		info.DaysRemaining = 0
+	}
+
+	return info
+}
+
+// buildCertificateInfo describes a single certificate.
+func buildCertificateInfo(cert *x509.Certificate, now time.Time) CertificateInfo {
+	ipAddresses := make([]string, 0, len(cert.IPAddresses))
+	for _, ip := range cert.IPAddresses {
+		ipAddresses = append(ipAddresses, ip.String())
+	}
+
+	fingerprint := sha256.Sum256(cert.Raw)
+
+	return CertificateInfo{
+		Subject:            cert.Subject.String(),
+		CommonName:         cert.Subject.CommonName,
+		DNSNames:           cert.DNSNames,
+		IPAddresses:        ipAddresses,
+		Issuer:             cert.Issuer.String(),
+		IssuerCommonName:   cert.Issuer.CommonName,
+		SerialNumber:       cert.SerialNumber.String(),
+		NotBefore:          cert.NotBefore.UTC(),
+		NotAfter:           cert.NotAfter.UTC(),
+		DaysRemaining:      int(math.Floor(cert.NotAfter.Sub(now).Hours() / 24)),
+		KeyType:            keyType(cert),
+		SignatureAlgorithm: cert.SignatureAlgorithm.String(),
+		FingerprintSHA256:  hex.EncodeToString(fingerprint[:]),
+	}
+}
+
+// keyType describes a certificate's public key, e.g. RSA-2048 or ECDSA-P256.
+func keyType(cert *x509.Certificate) string {
+	switch key := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		return fmt.Sprintf("RSA-%d", key.N.BitLen())
+	case *ecdsa.PublicKey:
+		return "ECDSA-" + key.Curve.Params().Name
+	case ed25519.PublicKey:
+		return "Ed25519"
+	default:
+		return cert.PublicKeyAlgorithm.String()
+	}
+}
+
+// applyCertificateExpiry escalates a result's status when the certificate chain
+// expires within the check's degraded or failed threshold.
+func applyCertificateExpiry(check *models.Check, info *TLSInfo, status models.CheckRunStatus, reason *models.FailureReason) (models.CheckRunStatus, *models.FailureReason) {
+	if info == nil || len(info.Chain) == 0 || status == models.CheckRunStatusFailing {
+		return status, reason
+	}
+
+	if check.CertExpiryFailedDays != nil && info.DaysRemaining < *check.CertExpiryFailedDays {
+		return models.CheckRunStatusFailing, failureReasonPtr(models.FailureCertExpiring)
+	}
+	if check.CertExpiryDegradedDays != nil && info.DaysRemaining < *check.CertExpiryDegradedDays {
+		return models.CheckRunStatusDegraded, reason
+	}
+
+	return status, reason
+}
@@ -1 +1,132 @@
 package clickhouse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"pulse/internal/store"
+)
+
+// bucketExpressions truncate created_at to the start of a time bucket, matching
+// DATE_TRUNC in Postgres
+var bucketExpressions = map[string]string{
+	"second": "toStartOfSecond(created_at)",
+	"minute": "toStartOfMinute(created_at)",
+	"hour":   "toStartOfHour(created_at)",
+	"day":    "toStartOfDay(created_at)",
+	"week":   "toMonday(created_at)",
+}
+
+// GetCheckRunsDataRange returns the first and last run of a check within the time
+// range, or nil, nil if there are none.
+func (c *Client) GetCheckRunsDataRange(ctx context.Context, checkID uuid.UUID, startTime, endTime time.Time) (*time.Time, *time.Time, error) {
+	var count uint64
+	var minTime, maxTime time.Time
+	if err := c.conn.QueryRow(ctx, `
+		SELECT count(), min(created_at), max(created_at)
+		FROM check_runs
+		WHERE check_id = ? AND created_at >= ? AND created_at <= ?
+	`, checkID, startTime, endTime).Scan(&count, &minTime, &maxTime); err != nil {
+		return nil, nil, fmt.Errorf("failed to query data range: %w", err)
+	}
+
+	if count == 0 {
+		return nil, nil, nil
+	}
+	return &minTime, &maxTime, nil
+}
+
+// GetCheckUptimeData returns the uptime of a check per time bucket, like
+// store.GetCheckUptimeData. Runs during maintenance are excluded.
+func (c *Client) GetCheckUptimeData(ctx context.Context, checkID uuid.UUID, startTime, endTime time.Time, timeBucket string) ([]store.UptimeDataPoint, error) {
+	bucket, ok := bucketExpressions[timeBucket]
+	if !ok {
+		bucket = bucketExpressions["hour"]
+	}
+
+	rows, err := c.conn.Query(ctx, fmt.Sprintf(`
+		SELECT
+			toDateTime(%s, 'UTC') AS time_bucket,
+			count() AS total,
+			countIf(status = 'passing') AS passing,
+			countIf(status = 'degraded') AS degraded,
+			countIf(status = 'failing') AS failing
+		FROM check_runs
+		WHERE check_id = ?
+			AND created_at >= ?
+			AND created_at <= ?
+			AND NOT in_maintenance
+		GROUP BY time_bucket
+		ORDER BY time_bucket ASC
+	`, bucket), checkID, startTime, endTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query uptime: %w", err)
+	}
+	defer rows.Close()
+
+	dataPoints := make([]store.UptimeDataPoint, 0)
+	for rows.Next() {
+		var timestamp time.Time
+		var total, passing, degraded, failing uint64
+		if err := rows.Scan(&timestamp, &total, &passing, &degraded, &failing); err != nil {
+			return nil, fmt.Errorf("failed to scan uptime: %w", err)
+		}
+
+		point := store.UptimeDataPoint{
+			Timestamp: timestamp.UTC(),
+			TotalRuns: int(total),
+			Passing:   int(passing),
+			Degraded:  int(degraded),
+			Failing:   int(failing),
+		}
+		// Degraded is considered "up" but not optimal
+		if total > 0 {
+			point.UptimePercentage = float64(passing+degraded) / float64(total) * 100.0
+		}
+		dataPoints = append(dataPoints, point)
+	}
+
+	return dataPoints, rows.Err()
+}
+
+// GetCheckTimingsData returns the network timings of each run of a check within
+// the time range, like store.GetCheckTimingsData without rollups.
+func (c *Client) GetCheckTimingsData(ctx context.Context, checkID uuid.UUID, startTime, endTime time.Time) ([]store.TimingDataPoint, error) {
+	rows, err := c.conn.Query(ctx, `
+		SELECT id, created_at, network_timings
+		FROM check_runs
+		WHERE check_id = ? AND created_at >= ? AND created_at <= ?
+		ORDER BY created_at ASC
+	`, checkID, startTime, endTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query timings: %w", err)
+	}
+	defer rows.Close()
+
+	dataPoints := make([]store.TimingDataPoint, 0)
+	for rows.Next() {
+		var id uuid.UUID
+		var createdAt time.Time
+		var networkTimings string
+		if err := rows.Scan(&id, &createdAt, &networkTimings); err != nil {
+			return nil, fmt.Errorf("failed to scan timings: %w", err)
+		}
+
+		var timings map[string]interface{}
+		if err := json.Unmarshal([]byte(networkTimings), &timings); err != nil || len(timings) == 0 {
+			continue
+		}
+
+		dataPoints = append(dataPoints, store.TimingDataPoint{
+			RunID:          id,
+			Timestamp:      createdAt.UTC(),
+			NetworkTimings: timings,
+		})
+	}
+
+	return dataPoints, rows.Err()
+}
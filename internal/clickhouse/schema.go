@@ -6,9 +6,47 @@ import (
 	"log"
 )
 
+// checkRunsTable holds the run history of checks for analytics. The durations of
+// the network phases of a run are flattened into columns, in microseconds, and are
+// null when the run didn't reach the phase. The network timings are also kept as
+// stored in Postgres, so timings read from either have the same fields.
+const checkRunsTable = `
+	CREATE TABLE IF NOT EXISTS check_runs (
+		id UUID,
+		check_id UUID,
+		project_id UUID,
+		region_id UUID,
+		region_code LowCardinality(String),
+		check_type LowCardinality(String),
+		status LowCardinality(String),
+		failure_reason LowCardinality(String),
+		response_status_code Nullable(Int32),
+		in_maintenance Bool,
+		inverted Bool,
+		run_started_at DateTime64(6, 'UTC'),
+		run_ended_at DateTime64(6, 'UTC'),
+		created_at DateTime64(6, 'UTC'),
+		ip_version LowCardinality(String),
+		ip_address String,
+		connection_reused Bool,
+		response_size_bytes Int64,
+		dns_duration_us Nullable(UInt32),
+		tcp_duration_us Nullable(UInt32),
+		tls_duration_us Nullable(UInt32),
+		request_duration_us Nullable(UInt32),
+		ttfb_us Nullable(UInt32),
+		download_us Nullable(UInt32),
+		response_time_us Nullable(UInt32),
+		network_timings String
+	)
+	ENGINE = MergeTree
+	PARTITION BY toYYYYMM(created_at)
+	ORDER BY (check_id, created_at)
+`
+
 // InitSchema creates all necessary tables in ClickHouse
 func (c *Client) InitSchema(ctx context.Context) error {
-	tables := []string{}
+	tables := []string{checkRunsTable}
 
 	for _, tableSQL := range tables {
 		if err := c.conn.Exec(ctx, tableSQL); err != nil {
@@ -1 +1,227 @@
 package clickhouse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"pulse/internal/models"
+)
+
+const (
+	// writerBufferSize is how many runs wait for the writer before new ones are dropped
+	writerBufferSize = 10000
+	// writerBatchSize is how many runs are inserted at once
+	writerBatchSize = 1000
+	// writerFlushInterval is how long a run waits at most for its batch to fill up
+	writerFlushInterval = 5 * time.Second
+	// writerFlushTimeout bounds a single batch insert
+	writerFlushTimeout = 30 * time.Second
+)
+
+// CheckRun is a row of the check_runs table
+type CheckRun struct {
+	ID                 uuid.UUID
+	CheckID            uuid.UUID
+	ProjectID          uuid.UUID
+	RegionID           uuid.UUID
+	RegionCode         string
+	CheckType          string
+	Status             string
+	FailureReason      string
+	ResponseStatusCode *int32
+	InMaintenance      bool
+	Inverted           bool
+	RunStartedAt       time.Time
+	RunEndedAt         time.Time
+	CreatedAt          time.Time
+	IPVersion          string
+	IPAddress          string
+	ConnectionReused   bool
+	ResponseSizeBytes  int64
+
+	// Phase durations in microseconds, nil when the run didn't reach the phase
+	DNSDurationUs     *uint32
+	TCPDurationUs     *uint32
+	TLSDurationUs     *uint32
+	RequestDurationUs *uint32
+	TTFBUs            *uint32
+	DownloadUs        *uint32
+	ResponseTimeUs    *uint32
+
+	// NetworkTimings is the run's network timings JSON
+	NetworkTimings string
+}
+
+// NewCheckRun flattens a stored run of a check into a check_runs row
+func NewCheckRun(run *models.CheckRun, check *models.Check, regionCode string) CheckRun {
+	row := CheckRun{
+		ID:                 run.ID,
+		CheckID:            run.CheckID,
+		ProjectID:          check.ProjectID,
+		RegionID:           run.RegionID,
+		RegionCode:         regionCode,
+		CheckType:          string(check.Type),
+		Status:             string(run.Status),
+		ResponseStatusCode: run.ResponseStatusCode,
+		InMaintenance:      run.InMaintenance,
+		Inverted:           run.Inverted,
+		RunStartedAt:       run.RunStartedAt,
+		RunEndedAt:         run.RunEndedAt,
+		CreatedAt:          run.CreatedAt,
+		IPVersion:          run.IPVersion,
+		IPAddress:          run.IPAddress,
+		ConnectionReused:   run.ConnectionReused,
+		ResponseSizeBytes:  run.ResponseSizeBytes,
+		NetworkTimings:     string(run.NetworkTimings),
+	}
+	if run.FailureReason != nil {
+		row.FailureReason = string(*run.FailureReason)
+	}
+
+	var timings map[string]interface{}
+	if err := json.Unmarshal(run.NetworkTimings, &timings); err == nil {
+		for key, column := range map[string]**uint32{
+			"dns_duration_us":     &row.DNSDurationUs,
+			"tcp_duration_us":     &row.TCPDurationUs,
+			"tls_duration_us":     &row.TLSDurationUs,
+			"request_duration_us": &row.RequestDurationUs,
+			"ttfb_us":             &row.TTFBUs,
+			"download_us":         &row.DownloadUs,
+			"response_time_us":    &row.ResponseTimeUs,
+		} {
+			if us, ok := timings[key].(float64); ok && us >= 0 {
+				value := uint32(us)
+				*column = &value
+			}
+		}
+	}
+
+	return row
+}
+
+// Writer inserts check runs into ClickHouse asynchronously, in batches. Runs are
+// still stored in Postgres, so a run the writer drops is only missing from analytics.
+type Writer struct {
+	client *Client
+	runs   chan CheckRun
+	done   chan struct{}
+}
+
+// NewWriter starts a writer. Close flushes the runs it buffered.
+func NewWriter(c *Client) *Writer {
+	w := &Writer{
+		client: c,
+		runs:   make(chan CheckRun, writerBufferSize),
+		done:   make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// Write queues a run for insertion without blocking. The run is dropped if the
+// buffer is full.
+func (w *Writer) Write(run CheckRun) {
+	select {
+	case w.runs <- run:
+	default:
+		log.Printf("ClickHouse writer buffer full, dropping run %s", run.ID)
+	}
+}
+
+// Close flushes the buffered runs and stops the writer. No runs may be written after.
+func (w *Writer) Close() {
+	close(w.runs)
+	<-w.done
+}
+
+func (w *Writer) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(writerFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]CheckRun, 0, writerBatchSize)
+	for {
+		select {
+		case run, ok := <-w.runs:
+			if !ok {
+				w.flush(batch)
+				return
+			}
+			batch = append(batch, run)
+			if len(batch) >= writerBatchSize {
+				w.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			w.flush(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+// flush inserts a batch of runs. A failed batch is logged and dropped.
+func (w *Writer) flush(batch []CheckRun) {
+	if len(batch) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), writerFlushTimeout)
+	defer cancel()
+
+	if err := w.client.InsertCheckRuns(ctx, batch); err != nil {
+		log.Printf("Error writing %d check runs to ClickHouse: %v", len(batch), err)
+	}
+}
+
+// InsertCheckRuns inserts runs into the check_runs table
+func (c *Client) InsertCheckRuns(ctx context.Context, runs []CheckRun) error {
+	batch, err := c.conn.PrepareBatch(ctx, "INSERT INTO check_runs")
+	if err != nil {
+		return fmt.Errorf("failed to prepare batch: %w", err)
+	}
+
+	for _, run := range runs {
+		if err := batch.Append(
+			run.ID,
+			run.CheckID,
+			run.ProjectID,
+			run.RegionID,
+			run.RegionCode,
+			run.CheckType,
+			run.Status,
+			run.FailureReason,
+			run.ResponseStatusCode,
+			run.InMaintenance,
+			run.Inverted,
+			run.RunStartedAt,
+			run.RunEndedAt,
+			run.CreatedAt,
+			run.IPVersion,
+			run.IPAddress,
+			run.ConnectionReused,
+			run.ResponseSizeBytes,
+			run.DNSDurationUs,
+			run.TCPDurationUs,
+			run.TLSDurationUs,
+			run.RequestDurationUs,
+			run.TTFBUs,
+			run.DownloadUs,
+			run.ResponseTimeUs,
+			run.NetworkTimings,
+		); err != nil {
+			batch.Abort()
+			return fmt.Errorf("failed to append run %s: %w", run.ID, err)
+		}
+	}
+
+	if err := batch.Send(); err != nil {
+		return fmt.Errorf("failed to send batch: %w", err)
+	}
+	return nil
+}
@@ -182,3 +182,41 @@ func (s *Service) SendEmailVerificationAsync(ctx context.Context, to, verificati
 		}
 	}()
 }
+
+// AlertDetails describes a check status change rendered into alert emails
+type AlertDetails struct {
+	CheckName      string
+	ProjectName    string
+	Status         string
+	PreviousStatus string
+	RegionName     string
+	FailureReason  string
+	CheckURL       string
+	OccurredAt     time.Time
+}
+
+// SendAlertEmail sends a check alert email synchronously
+func (s *Service) SendAlertEmail(ctx context.Context, to string, details *AlertDetails) error {
+	// Render HTML template
+	var htmlBuf bytes.Buffer
+	if err := s.tmpl.ExecuteTemplate(&htmlBuf, "alert.html", details); err != nil {
+		return fmt.Errorf("failed to render HTML template: %w", err)
+	}
+
+	// Render text template
+	var textBuf bytes.Buffer
+	if err := s.tmpl.ExecuteTemplate(&textBuf, "alert.txt", details); err != nil {
+		return fmt.Errorf("failed to render text template: %w", err)
+	}
+
+	subject := fmt.Sprintf("[%s] %s is %s", details.ProjectName, details.CheckName, details.Status)
+
+	emailMsg := &Email{
+		To:       to,
+		Subject:  subject,
+		HTMLBody: htmlBuf.String(),
+		TextBody: textBuf.String(),
+	}
+
+	return s.backend.SendEmail(ctx, emailMsg)
+}
@@ -0,0 +1,342 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"pulse/internal/middleware"
+	"pulse/internal/models"
+	"pulse/internal/notifier"
+	"pulse/internal/store"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+type ChannelHandler struct {
+	store      *store.Store
+	dispatcher *notifier.Dispatcher
+}
+
+func NewChannelHandler(s *store.Store, d *notifier.Dispatcher) *ChannelHandler {
+	return &ChannelHandler{store: s, dispatcher: d}
+}
+
+// CreateChannel handles POST /projects/:projectId/channels
+func (h *ChannelHandler) CreateChannel(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	projectID, err := uuid.Parse(c.Param("projectId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	isMember, err := h.store.IsProjectMember(projectID, userID)
+	if err != nil || !isMember {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+
+	var req struct {
+		Name      string                         `json:"name" binding:"required"`
+		Type      models.NotificationChannelType `json:"type" binding:"required"`
+		Config    datatypes.JSON                 `json:"config" binding:"required"`
+		IsEnabled *bool                          `json:"is_enabled"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.dispatcher.ValidateConfig(req.Type, req.Config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	channel := &models.NotificationChannel{
+		Name:      req.Name,
+		Type:      req.Type,
+		Config:    req.Config,
+		IsEnabled: true,
+		ProjectID: projectID,
+	}
+	if req.IsEnabled != nil {
+		channel.IsEnabled = *req.IsEnabled
+	}
+
+	if err := h.store.CreateNotificationChannel(channel); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create channel"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, channel)
+}
+
+// ListChannels handles GET /projects/:projectId/channels
+func (h *ChannelHandler) ListChannels(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	projectID, err := uuid.Parse(c.Param("projectId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	isMember, err := h.store.IsProjectMember(projectID, userID)
+	if err != nil || !isMember {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+
+	channels, err := h.store.GetNotificationChannelsByProject(projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list channels"})
+		return
+	}
+
+	c.JSON(http.StatusOK, channels)
+}
+
+// GetChannel handles GET /projects/:projectId/channels/:channelId
+func (h *ChannelHandler) GetChannel(c *gin.Context) {
+	channel, ok := h.loadChannel(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, channel)
+}
+
+// UpdateChannel handles PUT /projects/:projectId/channels/:channelId
+func (h *ChannelHandler) UpdateChannel(c *gin.Context) {
+	channel, ok := h.loadChannel(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Name      *string                         `json:"name"`
+		Type      *models.NotificationChannelType `json:"type"`
+		Config    datatypes.JSON                  `json:"config"`
+		IsEnabled *bool                           `json:"is_enabled"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Name != nil {
+		channel.Name = *req.Name
+	}
+	if req.Type != nil {
+		channel.Type = *req.Type
+	}
+	if req.Config != nil {
+		channel.Config = req.Config
+	}
+	if req.IsEnabled != nil {
+		channel.IsEnabled = *req.IsEnabled
+	}
+
+	if err := h.dispatcher.ValidateConfig(channel.Type, channel.Config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.store.UpdateNotificationChannel(channel); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update channel"})
+		return
+	}
+
+	c.JSON(http.StatusOK, channel)
+}
+
+// DeleteChannel handles DELETE /projects/:projectId/channels/:channelId
+func (h *ChannelHandler) DeleteChannel(c *gin.Context) {
+	channel, ok := h.loadChannel(c)
+	if !ok {
+		return
+	}
+
+	if err := h.store.DeleteNotificationChannel(channel.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete channel"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Channel deleted"})
+}
+
+// TestChannel handles POST /projects/:projectId/channels/:channelId/test
+func (h *ChannelHandler) TestChannel(c *gin.Context) {
+	channel, ok := h.loadChannel(c)
+	if !ok {
+		return
+	}
+
+	project, err := h.store.GetProject(channel.ProjectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load project"})
+		return
+	}
+
+	n := &notifier.Notification{
+		CheckName:      "Test notification",
+		ProjectID:      project.ID,
+		ProjectName:    project.Name,
+		RegionName:     "Test",
+		Status:         models.CheckRunStatusFailing,
+		PreviousStatus: models.CheckRunStatusPassing,
+		OccurredAt:     time.Now(),
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), notifier.SendTimeout)
+	defer cancel()
+
+	if err := h.dispatcher.Send(ctx, channel, n); err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Test notification sent"})
+}
+
+// AddChannelToCheck handles POST /projects/:projectId/checks/:checkId/channels/:channelId
+func (h *ChannelHandler) AddChannelToCheck(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	projectID, err := uuid.Parse(c.Param("projectId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	isMember, err := h.store.IsProjectMember(projectID, userID)
+	if err != nil || !isMember {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+
+	checkID, err := uuid.Parse(c.Param("checkId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid check ID"})
+		return
+	}
+
+	channelID, err := uuid.Parse(c.Param("channelId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid channel ID"})
+		return
+	}
+
+	if err := h.store.AddChannelToCheck(checkID, channelID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add channel to check"})
+		return
+	}
+
+	check, err := h.store.GetCheck(checkID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load check"})
+		return
+	}
+
+	c.JSON(http.StatusOK, check)
+}
+
+// RemoveChannelFromCheck handles DELETE /projects/:projectId/checks/:checkId/channels/:channelId
+func (h *ChannelHandler) RemoveChannelFromCheck(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	projectID, err := uuid.Parse(c.Param("projectId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	isMember, err := h.store.IsProjectMember(projectID, userID)
+	if err != nil || !isMember {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+
+	checkID, err := uuid.Parse(c.Param("checkId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid check ID"})
+		return
+	}
+
+	channelID, err := uuid.Parse(c.Param("channelId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid channel ID"})
+		return
+	}
+
+	if err := h.store.RemoveChannelFromCheck(checkID, channelID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove channel from check"})
+		return
+	}
+
+	check, err := h.store.GetCheck(checkID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load check"})
+		return
+	}
+
+	c.JSON(http.StatusOK, check)
+}
+
+// loadChannel authorizes the request and loads the channel from the path,
+// writing the error response itself when it fails
+func (h *ChannelHandler) loadChannel(c *gin.Context) (*models.NotificationChannel, bool) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return nil, false
+	}
+
+	projectID, err := uuid.Parse(c.Param("projectId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return nil, false
+	}
+
+	isMember, err := h.store.IsProjectMember(projectID, userID)
+	if err != nil || !isMember {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return nil, false
+	}
+
+	channelID, err := uuid.Parse(c.Param("channelId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid channel ID"})
+		return nil, false
+	}
+
+	channel, err := h.store.GetNotificationChannel(channelID)
+	if err != nil || channel.ProjectID != projectID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Channel not found"})
+		return nil, false
+	}
+
+	return channel, true
+}
@@ -1,29 +1,46 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
-	"pulse/internal/alerter"
 	"pulse/internal/checker"
+	"pulse/internal/clickhouse"
 	"pulse/internal/middleware"
 	"pulse/internal/models"
 	"pulse/internal/redis"
 	"pulse/internal/store"
 )
 
+const (
+	// maxStreamedRuns bounds the runs a single stream follows
+	maxStreamedRuns = 50
+	// streamTimeout is how long a stream waits for its runs
+	streamTimeout = 5 * time.Minute
+	// streamKeepAlive is how often an idle stream sends a comment
+	streamKeepAlive = 15 * time.Second
+)
+
 type CheckRunHandler struct {
-	store   *store.Store
-	redis   *redis.Client
-	alerter *alerter.Alerter
+	store      *store.Store
+	redis      *redis.Client
+	clickhouse *clickhouse.Client
 }
 
-func NewCheckRunHandler(s *store.Store, r *redis.Client, a *alerter.Alerter) *CheckRunHandler {
-	return &CheckRunHandler{store: s, redis: r, alerter: a}
+// NewCheckRunHandler creates the handler. Uptime and timings are served from
+// ClickHouse when ch is set, and from Postgres otherwise.
+func NewCheckRunHandler(s *store.Store, r *redis.Client, ch *clickhouse.Client) *CheckRunHandler {
+	return &CheckRunHandler{store: s, redis: r, clickhouse: ch}
 }
 
 // GetCheckRun handles GET /projects/:projectId/checks/:checkId/runs/:runId
@@ -342,7 +359,7 @@ func (h *CheckRunHandler) GetCheckUptime(c *gin.Context) {
 		}
 	}
 
-	uptimeData, err := h.store.GetCheckUptimeData(checkID, startTime, endTime, timeBucket)
+	uptimeData, err := h.checkUptimeData(c.Request.Context(), checkID, startTime, endTime, timeBucket)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch uptime data"})
 		return
@@ -471,7 +488,7 @@ func (h *CheckRunHandler) GetCheckTimings(c *gin.Context) {
 		}
 	}
 
-	timingsData, err := h.store.GetCheckTimingsData(checkID, startTime, endTime)
+	timingsData, err := h.checkTimingsData(c.Request.Context(), checkID, startTime, endTime)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch timings data"})
 		return
@@ -487,9 +504,69 @@ func (h *CheckRunHandler) GetCheckTimings(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// clickhouseCovers reports whether ClickHouse holds the runs of a check within the
+// time range. Runs stored before ClickHouse was configured and rollups of runs past
+// their project's retention are only in Postgres.
+func (h *CheckRunHandler) clickhouseCovers(ctx context.Context, checkID uuid.UUID, startTime, endTime time.Time) bool {
+	if h.clickhouse == nil {
+		return false
+	}
+
+	firstRun, _, err := h.clickhouse.GetCheckRunsDataRange(ctx, checkID, startTime, endTime)
+	if err != nil {
+		log.Printf("Error fetching data range of check %s from ClickHouse, falling back to Postgres: %v", checkID, err)
+		return false
+	}
+	if firstRun == nil {
+		return false
+	}
+
+	// Postgres has nothing before the first run in ClickHouse
+	firstStored, _, err := h.store.GetCheckRunsDataRange(checkID, startTime, *firstRun)
+	if err != nil {
+		log.Printf("Error fetching data range of check %s, falling back to Postgres: %v", checkID, err)
+		return false
+	}
+	return firstStored == nil || !firstStored.Before(*firstRun)
+}
+
+// checkUptimeData returns the uptime of a check from ClickHouse if it covers the
+// time range, falling back to Postgres.
+func (h *CheckRunHandler) checkUptimeData(ctx context.Context, checkID uuid.UUID, startTime, endTime time.Time, timeBucket string) ([]store.UptimeDataPoint, error) {
+	if h.clickhouseCovers(ctx, checkID, startTime, endTime) {
+		data, err := h.clickhouse.GetCheckUptimeData(ctx, checkID, startTime, endTime, timeBucket)
+		if err == nil {
+			return data, nil
+		}
+		log.Printf("Error fetching uptime of check %s from ClickHouse, falling back to Postgres: %v", checkID, err)
+	}
+	return h.store.GetCheckUptimeData(checkID, startTime, endTime, timeBucket)
+}
+
+// checkTimingsData returns the timings of a check's runs from ClickHouse if it
+// covers the time range, falling back to Postgres.
+func (h *CheckRunHandler) checkTimingsData(ctx context.Context, checkID uuid.UUID, startTime, endTime time.Time) ([]store.TimingDataPoint, error) {
+	if h.clickhouseCovers(ctx, checkID, startTime, endTime) {
+		data, err := h.clickhouse.GetCheckTimingsData(ctx, checkID, startTime, endTime)
+		if err == nil {
+			return data, nil
+		}
+		log.Printf("Error fetching timings of check %s from ClickHouse, falling back to Postgres: %v", checkID, err)
+	}
+	return h.store.GetCheckTimingsData(checkID, startTime, endTime)
+}
+
 // TriggerCheckRun handles POST /projects/:projectId/checks/:checkId/runs/trigger
-// This endpoint manually triggers a check run synchronously and returns the results
+// It enqueues a high-priority run of the check in each selected region (all regions
+// of the check by default) and returns the IDs the runs will be stored under. The
+// results are followed with StreamCheckRuns.
 func (h *CheckRunHandler) TriggerCheckRun(c *gin.Context) {
+	// The API can run without Redis, which runs are enqueued and followed through
+	if h.redis == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Redis is required for triggering runs"})
+		return
+	}
+
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
@@ -514,6 +591,15 @@ func (h *CheckRunHandler) TriggerCheckRun(c *gin.Context) {
 		return
 	}
 
+	var req struct {
+		RegionIDs []uuid.UUID `json:"region_ids,omitempty"`
+	}
+	// The body is optional
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Verify the check exists and belongs to the project
 	check, err := h.store.GetCheck(checkID)
 	if err != nil {
@@ -526,79 +612,195 @@ func (h *CheckRunHandler) TriggerCheckRun(c *gin.Context) {
 		return
 	}
 
+	// Passive checks such as heartbeats only produce runs when pinged
+	if checkType, _ := checker.LookupType(check.Type); checkType.Passive {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s checks can't be triggered", check.Type)})
+		return
+	}
+
 	// Ensure check has at least one region
 	if len(check.Regions) == 0 {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Check has no regions configured"})
 		return
 	}
 
-	// Use the first region for execution
-	regionID := check.Regions[0].ID
+	regions := check.Regions
+	if len(req.RegionIDs) > 0 {
+		regions = make([]models.Region, 0, len(req.RegionIDs))
+		for _, regionID := range req.RegionIDs {
+			found := false
+			for _, region := range check.Regions {
+				if region.ID == regionID {
+					regions = append(regions, region)
+					found = true
+					break
+				}
+			}
+			if !found {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Region " + regionID.String() + " is not configured for the check"})
+				return
+			}
+		}
+	}
 
-	// Track run start time
-	runStartedAt := time.Now().UTC()
+	type PendingRun struct {
+		ID         uuid.UUID `json:"id"`
+		RegionID   uuid.UUID `json:"region_id"`
+		RegionCode string    `json:"region_code"`
+	}
 
-	// Execute check synchronously
-	result := checker.Execute(check)
+	runs := make([]PendingRun, 0, len(regions))
+	for _, region := range regions {
+		runID, err := uuid.NewV7()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to trigger check run"})
+			return
+		}
 
-	// Track run end time
-	runEndedAt := time.Now().UTC()
+		if err := h.redis.EnqueueJob(redis.Job{
+			CheckID:    check.ID,
+			ProjectID:  check.ProjectID,
+			RegionCode: region.Code,
+			Priority:   true,
+			RunID:      runID,
+		}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to trigger check run"})
+			return
+		}
 
-	// Convert checker.Result to models.CheckRun
-	checkRun := &models.CheckRun{
-		Status:             result.Status,
-		FailureReason:      result.FailureReason,
-		ResponseStatusCode: result.ResponseStatus,
+		runs = append(runs, PendingRun{ID: runID, RegionID: region.ID, RegionCode: region.Code})
+	}
 
-		// Run timeline
-		RunStartedAt: runStartedAt,
-		RunEndedAt:   runEndedAt,
+	c.JSON(http.StatusAccepted, gin.H{"runs": runs})
+}
 
-		// Request timeline (from result)
-		RequestStartedAt: result.RequestStartedAt,
-		FirstByteAt:      result.FirstByteAt,
-		ResponseEndedAt:  result.ResponseEndedAt,
+// StreamCheckRuns handles GET /projects/:projectId/checks/:checkId/runs/stream
+// It streams the runs given in run_ids as Server-Sent Events: a "run" event for
+// each run once a worker stored it, then "done" when all arrived, or "timeout".
+func (h *CheckRunHandler) StreamCheckRuns(c *gin.Context) {
+	if h.redis == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Redis is required for streaming runs"})
+		return
+	}
 
-		// Metadata
-		ConnectionReused:  result.ConnectionReused,
-		IPVersion:         result.IPVersion,
-		IPAddress:         result.IPAddress,
-		ResponseSizeBytes: result.ResponseSizeBytes,
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
 
-		// JSON fields
-		AssertionResults: result.AssertionResults,
-		PlaywrightReport: result.PlaywrightReport,
-		NetworkTimings:   result.NetworkTimings,
-		Response:         result.Response,
+	projectID, err := uuid.Parse(c.Param("projectId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
 
-		RegionID: regionID,
-		CheckID:  check.ID,
+	isMember, err := h.store.IsProjectMember(projectID, userID)
+	if err != nil || !isMember {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
 	}
 
-	// Create the check run
-	createdRun, err := h.store.CreateCheckRun(checkRun)
+	checkID, err := uuid.Parse(c.Param("checkId"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save check run"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid check ID"})
+		return
+	}
+
+	pending := make(map[uuid.UUID]bool)
+	for _, value := range strings.Split(c.Query("run_ids"), ",") {
+		if value == "" {
+			continue
+		}
+		runID, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid run ID"})
+			return
+		}
+		pending[runID] = true
+	}
+	if len(pending) == 0 || len(pending) > maxStreamedRuns {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "run_ids must list between 1 and " + strconv.Itoa(maxStreamedRuns) + " run IDs"})
 		return
 	}
 
-	// Process alerts
-	if h.alerter != nil {
-		h.alerter.ProcessCheckResult(check, createdRun)
+	check, err := h.store.GetCheck(checkID)
+	if err != nil || check.ProjectID != projectID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Check not found"})
+		return
 	}
 
-	// Update check status
-	interval, err := time.ParseDuration(check.Interval)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), streamTimeout)
+	defer cancel()
+
+	// Subscribe before looking up stored runs, so none is missed in between
+	sub, err := h.redis.SubscribeCheckRuns(ctx, checkID)
 	if err != nil {
-		interval = 10 * time.Minute // Default to 10 minutes if parsing fails
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to follow check runs"})
+		return
 	}
-	nextRun := time.Now().UTC().Add(interval)
-	if err := h.store.UpdateCheckStatus(checkID, nextRun, result.Status); err != nil {
-		// Log error but don't fail the request
-		_ = err
+	defer sub.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	// send emits the runs among ids that are still pending and stored
+	send := func(ids []uuid.UUID) error {
+		runs, err := h.store.GetCheckRunsByIDs(checkID, ids)
+		if err != nil {
+			return err
+		}
+		for i := range runs {
+			if !pending[runs[i].ID] {
+				continue
+			}
+			delete(pending, runs[i].ID)
+			c.SSEvent("run", toCheckRunResponse(&runs[i]))
+		}
+		c.Writer.Flush()
+		return nil
 	}
 
-	// Convert to response with computed total_time_ms
-	response := toCheckRunResponse(createdRun)
-	c.JSON(http.StatusOK, response)
+	ids := make([]uuid.UUID, 0, len(pending))
+	for runID := range pending {
+		ids = append(ids, runID)
+	}
+	if err := send(ids); err != nil {
+		c.SSEvent("error", gin.H{"error": "Failed to load check runs"})
+		return
+	}
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+
+	for len(pending) > 0 {
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				c.SSEvent("timeout", gin.H{"pending": len(pending)})
+			}
+			return
+		case <-keepAlive.C:
+			// A comment line keeps proxies from closing an idle stream
+			if _, err := c.Writer.WriteString(": keep-alive\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case runID, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			if !pending[runID] {
+				continue
+			}
+			if err := send([]uuid.UUID{runID}); err != nil {
+				c.SSEvent("error", gin.H{"error": "Failed to load check runs"})
+				return
+			}
+		}
+	}
+
+	c.SSEvent("done", gin.H{})
 }
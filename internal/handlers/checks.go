@@ -1,7 +1,7 @@
 package handlers
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"net/http"
@@ -11,17 +11,23 @@ import (
 	"github.com/google/uuid"
 	"gorm.io/datatypes"
 
+	"pulse/internal/checker"
 	"pulse/internal/middleware"
 	"pulse/internal/models"
+	"pulse/internal/redis"
 	"pulse/internal/store"
 )
 
+// testCheckTimeout is how long a test run may take before TestCheck gives up
+const testCheckTimeout = 2 * time.Minute
+
 type CheckHandler struct {
 	store *store.Store
+	redis *redis.Client
 }
 
-func NewCheckHandler(s *store.Store) *CheckHandler {
-	return &CheckHandler{store: s}
+func NewCheckHandler(s *store.Store, r *redis.Client) *CheckHandler {
+	return &CheckHandler{store: s, redis: r}
 }
 
 // CheckListResponse represents a check in the list with metrics
@@ -47,6 +53,62 @@ type RunSummary struct {
 	Status      *string    `json:"status,omitempty"`
 }
 
+// CreateCheckRequest is the body of a request creating a check
+type CreateCheckRequest struct {
+	Name                  string         `json:"name" binding:"required"`
+	Type                  string         `json:"type" binding:"required"`
+	Host                  string         `json:"host"`
+	Port                  *int           `json:"port"`
+	Secure                *bool          `json:"secure"`
+	Method                string         `json:"method"`
+	Path                  string         `json:"path"`
+	QueryParams           datatypes.JSON `json:"query_params"`
+	Headers               datatypes.JSON `json:"headers"`
+	Body                  datatypes.JSON `json:"body"`
+	IPVersion             string         `json:"ip_version"`
+	SkipSSLVerification   *bool          `json:"skip_ssl_verification"`
+	FollowRedirects       *bool          `json:"follow_redirects"`
+	PlaywrightScript      *string        `json:"playwright_script,omitempty"`
+	Assertions            datatypes.JSON `json:"assertions"`
+	Steps                 datatypes.JSON `json:"steps,omitempty"`
+	PreScript             *string        `json:"pre_script,omitempty"`
+	PostScript            *string        `json:"post_script,omitempty"`
+	Interval              string         `json:"interval"`
+	Cron                  *string        `json:"cron,omitempty"`
+	Timezone              string         `json:"timezone"`
+	DegradedThreshold     int            `json:"degraded_threshold"`
+	DegradedThresholdUnit string         `json:"degraded_threshold_unit"`
+	FailedThreshold       int            `json:"failed_threshold"`
+	FailedThresholdUnit   string         `json:"failed_threshold_unit"`
+	CertDegradedDays      *int           `json:"cert_expiry_degraded_days,omitempty"`
+	CertFailedDays        *int           `json:"cert_expiry_failed_days,omitempty"`
+	Retries               string         `json:"retries"`
+	RetriesCount          *int           `json:"retries_count,omitempty"`
+	RetriesDelay          *int           `json:"retries_delay,omitempty"`
+	RetriesDelayUnit      *string        `json:"retries_delay_unit,omitempty"`
+	RetriesFactor         *float64       `json:"retries_factor,omitempty"`
+	RetriesJitter         *string        `json:"retries_jitter,omitempty"`
+	RetriesJitterFactor   *float64       `json:"retries_jitter_factor,omitempty"`
+	RetriesMaxDelay       *int           `json:"retries_max_delay,omitempty"`
+	RetriesMaxDelayUnit   *string        `json:"retries_max_delay_unit,omitempty"`
+	RetriesTimeout        *int           `json:"retries_timeout,omitempty"`
+	RetriesTimeoutUnit    *string        `json:"retries_timeout_unit,omitempty"`
+	IsEnabled             bool           `json:"is_enabled"`
+	IsMuted               bool           `json:"is_muted"`
+	ShouldFail            bool           `json:"should_fail"`
+	AlertFailureCount     *int           `json:"alert_failure_threshold,omitempty"`
+	AlertRecoveryCount    *int           `json:"alert_recovery_threshold,omitempty"`
+	AlertRegionQuorum     *int           `json:"alert_region_quorum,omitempty"`
+	TagIDs                []uuid.UUID    `json:"tag_ids,omitempty"`
+	RegionIDs             []uuid.UUID    `json:"region_ids,omitempty"`
+	ChannelIDs            []uuid.UUID    `json:"channel_ids,omitempty"`
+	DNSRecordType         *string        `json:"dns_record_type,omitempty"`
+	DNSResolver           *string        `json:"dns_resolver,omitempty"`
+	DNSResolverPort       *int           `json:"dns_resolver_port,omitempty"`
+	DNSResolverProtocol   *string        `json:"dns_resolver_protocol,omitempty"`
+	HeartbeatGrace        string         `json:"heartbeat_grace,omitempty"`
+}
+
 // CreateCheck handles POST /projects/:projectId/checks
 func (h *CheckHandler) CreateCheck(c *gin.Context) {
 	userID, ok := middleware.GetUserID(c)
@@ -67,49 +129,97 @@ func (h *CheckHandler) CreateCheck(c *gin.Context) {
 		return
 	}
 
+	var req CreateCheckRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	check, msg := buildCheck(projectID, &req)
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	// Checks can only notify the channels of their project
+	for _, channelID := range req.ChannelIDs {
+		channel, err := h.store.GetNotificationChannel(channelID)
+		if err != nil || channel.ProjectID != projectID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Notification channel not found"})
+			return
+		}
+	}
+
+	if err := h.store.CreateCheck(check); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create check"})
+		return
+	}
+
+	// Add tags if provided
+	if len(req.TagIDs) > 0 {
+		for _, tagID := range req.TagIDs {
+			_ = h.store.AddTagToCheck(check.ID, tagID)
+		}
+	}
+
+	// Add notification channels if provided
+	for _, channelID := range req.ChannelIDs {
+		if err := h.store.AddChannelToCheck(check.ID, channelID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to associate notification channel with check"})
+			return
+		}
+	}
+
+	// Add regions
+	for _, regionID := range req.RegionIDs {
+		if err := h.store.AddRegionToCheck(check.ID, regionID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to associate region with check"})
+			return
+		}
+	}
+
+	// Reload check with associations
+	check, err = h.store.GetCheck(check.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load check"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, check)
+}
+
+// TestCheck handles POST /projects/:projectId/checks/test
+// It runs the check described by a create request once in a region, the first of
+// its regions unless region_id is given, and returns the run. Nothing is stored.
+func (h *CheckHandler) TestCheck(c *gin.Context) {
+	// Test runs are handed to a worker and back through Redis
+	if h.redis == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Redis is required for test runs"})
+		return
+	}
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	projectID, err := uuid.Parse(c.Param("projectId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	isMember, err := h.store.IsProjectMember(projectID, userID)
+	if err != nil || !isMember {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+
 	var req struct {
-		Name                  string         `json:"name" binding:"required"`
-		Type                  string         `json:"type" binding:"required"`
-		Host                  string         `json:"host" binding:"required"`
-		Port                  *int           `json:"port"`
-		Secure                *bool          `json:"secure"`
-		Method                string         `json:"method"`
-		Path                  string         `json:"path"`
-		QueryParams           datatypes.JSON `json:"query_params"`
-		Headers               datatypes.JSON `json:"headers"`
-		Body                  datatypes.JSON `json:"body"`
-		IPVersion             string         `json:"ip_version"`
-		SkipSSLVerification   *bool          `json:"skip_ssl_verification"`
-		FollowRedirects       *bool          `json:"follow_redirects"`
-		PlaywrightScript      *string        `json:"playwright_script,omitempty"`
-		Assertions            datatypes.JSON `json:"assertions"`
-		PreScript             *string        `json:"pre_script,omitempty"`
-		PostScript            *string        `json:"post_script,omitempty"`
-		Interval              string         `json:"interval" binding:"required"`
-		DegradedThreshold     int            `json:"degraded_threshold"`
-		DegradedThresholdUnit string         `json:"degraded_threshold_unit"`
-		FailedThreshold       int            `json:"failed_threshold"`
-		FailedThresholdUnit   string         `json:"failed_threshold_unit"`
-		Retries               string         `json:"retries"`
-		RetriesCount          *int           `json:"retries_count,omitempty"`
-		RetriesDelay          *int           `json:"retries_delay,omitempty"`
-		RetriesDelayUnit      *string        `json:"retries_delay_unit,omitempty"`
-		RetriesFactor         *float64       `json:"retries_factor,omitempty"`
-		RetriesJitter         *string        `json:"retries_jitter,omitempty"`
-		RetriesJitterFactor   *float64       `json:"retries_jitter_factor,omitempty"`
-		RetriesMaxDelay       *int           `json:"retries_max_delay,omitempty"`
-		RetriesMaxDelayUnit   *string        `json:"retries_max_delay_unit,omitempty"`
-		RetriesTimeout        *int           `json:"retries_timeout,omitempty"`
-		RetriesTimeoutUnit    *string        `json:"retries_timeout_unit,omitempty"`
-		IsEnabled             bool           `json:"is_enabled"`
-		IsMuted               bool           `json:"is_muted"`
-		ShouldFail            bool           `json:"should_fail"`
-		TagIDs                []uuid.UUID    `json:"tag_ids,omitempty"`
-		RegionIDs             []uuid.UUID    `json:"region_ids,omitempty"`
-		DNSRecordType         *string        `json:"dns_record_type,omitempty"`
-		DNSResolver           *string        `json:"dns_resolver,omitempty"`
-		DNSResolverPort       *int           `json:"dns_resolver_port,omitempty"`
-		DNSResolverProtocol   *string        `json:"dns_resolver_protocol,omitempty"`
+		CreateCheckRequest
+		RegionID *uuid.UUID `json:"region_id,omitempty"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -117,23 +227,80 @@ func (h *CheckHandler) CreateCheck(c *gin.Context) {
 		return
 	}
 
-	// Validate that at least one region is required
-	if len(req.RegionIDs) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "At least one region is required"})
+	check, msg := buildCheck(projectID, &req.CreateCheckRequest)
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	// Passive checks such as heartbeats only produce runs when pinged
+	if checkType, _ := checker.LookupType(check.Type); checkType.Passive {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s checks can't be tested", check.Type)})
+		return
+	}
+
+	regionID := req.RegionIDs[0]
+	if req.RegionID != nil {
+		regionID = *req.RegionID
+	}
+	region, err := h.store.GetRegion(regionID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Region not found"})
 		return
 	}
 
-	checkType := models.CheckType(req.Type)
-	if checkType != models.CheckTypeHTTP && checkType != models.CheckTypeTCP &&
-		checkType != models.CheckTypeDNS && checkType != models.CheckTypeBrowser &&
-		checkType != models.CheckTypeHeartbeat {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid check type"})
+	job := redis.Job{
+		ID:         uuid.New(),
+		ProjectID:  projectID,
+		RegionCode: region.Code,
+		Priority:   true,
+		Check:      check,
+	}
+	if err := h.redis.EnqueueJob(job); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to run check"})
 		return
 	}
 
+	// Wait for the whole run, retries included
+	timeout := testCheckTimeout
+	if retriesTimeout := check.RetriesTimeoutDuration(); retriesTimeout+time.Minute > timeout {
+		timeout = retriesTimeout + time.Minute
+	}
+
+	var run models.CheckRun
+	if err := h.redis.WaitJobResult(c.Request.Context(), job.ID, timeout, &run); err != nil {
+		if errors.Is(err, redis.ErrJobTimeout) {
+			c.JSON(http.StatusGatewayTimeout, gin.H{"error": "Check run timed out"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to run check"})
+		return
+	}
+	run.Region = *region
+
+	c.JSON(http.StatusOK, toCheckRunResponse(&run))
+}
+
+// buildCheck validates a create request and builds the check it describes, with
+// defaults applied. It returns an error message, or an empty string if it is valid.
+func buildCheck(projectID uuid.UUID, req *CreateCheckRequest) (*models.Check, string) {
+	// Validate that at least one region is required
+	if len(req.RegionIDs) == 0 {
+		return nil, "At least one region is required"
+	}
+
+	if msg := validateAlertPolicy(req.AlertFailureCount, req.AlertRecoveryCount, req.AlertRegionQuorum, len(req.RegionIDs)); msg != "" {
+		return nil, msg
+	}
+
+	checkType, ok := checker.LookupType(models.CheckType(req.Type))
+	if !ok {
+		return nil, "Invalid check type"
+	}
+
 	check := &models.Check{
 		Name:                  req.Name,
-		Type:                  checkType,
+		Type:                  checkType.Name,
 		Host:                  req.Host,
 		Method:                req.Method,
 		Path:                  req.Path,
@@ -143,6 +310,7 @@ func (h *CheckHandler) CreateCheck(c *gin.Context) {
 		IPVersion:             models.IPVersionType(req.IPVersion),
 		PlaywrightScript:      req.PlaywrightScript,
 		Assertions:            req.Assertions,
+		Steps:                 req.Steps,
 		PreScript:             req.PreScript,
 		PostScript:            req.PostScript,
 		Interval:              req.Interval,
@@ -167,6 +335,16 @@ func (h *CheckHandler) CreateCheck(c *gin.Context) {
 		ProjectID:             projectID,
 	}
 
+	// Certificate expiry thresholds are unset unless given
+	check.CertExpiryDegradedDays = req.CertDegradedDays
+	check.CertExpiryFailedDays = req.CertFailedDays
+
+	// Checks run every interval unless a cron expression is given
+	if req.Cron != nil && *req.Cron != "" {
+		check.Cron = req.Cron
+	}
+	check.Timezone = req.Timezone
+
 	// Handle DNS fields
 	if req.DNSRecordType != nil {
 		check.DNSRecordType = (*models.DNSRecordType)(req.DNSRecordType)
@@ -226,14 +404,20 @@ func (h *CheckHandler) CreateCheck(c *gin.Context) {
 	if check.Interval == "" {
 		check.Interval = "10m"
 	}
+	if check.Timezone == "" {
+		check.Timezone = "UTC"
+	}
+	// Thresholds default to those of the check type
 	if check.DegradedThreshold == 0 {
-		check.DegradedThreshold = 3000
+		check.DegradedThreshold = checkType.Thresholds.Degraded
+		check.DegradedThresholdUnit = checkType.Thresholds.DegradedUnit
 	}
 	if check.DegradedThresholdUnit == "" {
 		check.DegradedThresholdUnit = models.UnitTypeMs
 	}
 	if check.FailedThreshold == 0 {
-		check.FailedThreshold = 5000
+		check.FailedThreshold = checkType.Thresholds.Failed
+		check.FailedThresholdUnit = checkType.Thresholds.FailedUnit
 	}
 	if check.FailedThresholdUnit == "" {
 		check.FailedThresholdUnit = models.UnitTypeMs
@@ -245,34 +429,50 @@ func (h *CheckHandler) CreateCheck(c *gin.Context) {
 		check.IsEnabled = true
 	}
 
-	if err := h.store.CreateCheck(check); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create check"})
-		return
+	// Alert policy defaults to alerting on the first status change in any region
+	check.AlertFailureThreshold = 1
+	check.AlertRecoveryThreshold = 1
+	check.AlertRegionQuorum = 1
+	if req.AlertFailureCount != nil {
+		check.AlertFailureThreshold = *req.AlertFailureCount
+	}
+	if req.AlertRecoveryCount != nil {
+		check.AlertRecoveryThreshold = *req.AlertRecoveryCount
+	}
+	if req.AlertRegionQuorum != nil {
+		check.AlertRegionQuorum = *req.AlertRegionQuorum
 	}
 
-	// Add tags if provided
-	if len(req.TagIDs) > 0 {
-		for _, tagID := range req.TagIDs {
-			_ = h.store.AddTagToCheck(check.ID, tagID)
+	if check.Type == models.CheckTypeHeartbeat {
+		check.HeartbeatGrace = req.HeartbeatGrace
+		if check.HeartbeatGrace == "" {
+			check.HeartbeatGrace = "5m"
 		}
 	}
 
-	// Add regions
-	for _, regionID := range req.RegionIDs {
-		if err := h.store.AddRegionToCheck(check.ID, regionID); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to associate region with check"})
-			return
-		}
+	if msg := validateSchedule(check); msg != "" {
+		return nil, msg
+	}
+	if msg := validateCertExpiry(check); msg != "" {
+		return nil, msg
+	}
+	if err := checker.ValidateCheck(check); err != nil {
+		return nil, err.Error()
+	}
+	if msg := validateScripts(check); msg != "" {
+		return nil, msg
 	}
 
-	// Reload check with associations
-	check, err = h.store.GetCheck(check.ID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load check"})
-		return
+	if check.Type == models.CheckTypeHeartbeat {
+		if msg := setupHeartbeat(check); msg != "" {
+			return nil, msg
+		}
+		// The first ping is due one interval plus grace after creation
+		nextRun := time.Now().UTC().Add(check.IntervalDuration() + check.HeartbeatGraceDuration())
+		check.NextRunAt = &nextRun
 	}
 
-	c.JSON(http.StatusCreated, check)
+	return check, ""
 }
 
 // GetCheck handles GET /projects/:projectId/checks/:checkId
@@ -307,6 +507,13 @@ func (h *CheckHandler) GetCheck(c *gin.Context) {
 		return
 	}
 
+	// Each region schedules and runs the check independently
+	check.RegionStates, err = h.store.GetCheckRegionStates(check.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get region states"})
+		return
+	}
+
 	c.JSON(http.StatusOK, check)
 }
 
@@ -331,189 +538,52 @@ func (h *CheckHandler) ListChecks(c *gin.Context) {
 	}
 
 	now := time.Now().UTC()
-	twentyFourHoursAgo := now.Add(-24 * time.Hour)
-	sevenDaysAgo := now.Add(-7 * 24 * time.Hour)
 
-	// Single optimized query using CTEs to get all data
+	// The runs of each check are summarized in its stats as they are stored
 	type CheckListRow struct {
-		ID                 uuid.UUID  `gorm:"column:id"`
-		Type               string     `gorm:"column:type"`
-		Name               string     `gorm:"column:name"`
-		Interval           string     `gorm:"column:interval"`
-		LastRun            *time.Time `gorm:"column:last_run_at"`
-		LastStatus         string     `gorm:"column:last_status"`
-		Last24Runs         string     `gorm:"column:last_24_runs"` // JSON array
-		Uptime24h          *float64   `gorm:"column:uptime_24h"`
-		Uptime7d           *float64   `gorm:"column:uptime_7d"`
-		AvgResponseTime24h *float64   `gorm:"column:avg_response_time_24h"`
-		P95ResponseTime24h *float64   `gorm:"column:p95_response_time_24h"`
+		ID         uuid.UUID  `gorm:"column:id"`
+		Type       string     `gorm:"column:type"`
+		Name       string     `gorm:"column:name"`
+		Interval   string     `gorm:"column:interval"`
+		LastRun    *time.Time `gorm:"column:last_run_at"`
+		LastStatus string     `gorm:"column:last_status"`
+		models.CheckStats
 	}
 
 	var rows []CheckListRow
 	err = h.store.DB().Raw(`
-		WITH checks_base AS (
-			SELECT
-				id,
-				type,
-				name,
-				interval,
-				last_run_at,
-				last_status
-			FROM checks
-			WHERE project_id = ? AND deleted_at IS NULL
-		),
-		ranked_runs AS (
-			SELECT
-				check_id,
-				status,
-				request_started_at,
-				response_ended_at,
-				created_at,
-				id,
-				ROW_NUMBER() OVER (PARTITION BY check_id ORDER BY created_at DESC, id DESC) as row_num
-			FROM check_runs
-			WHERE deleted_at IS NULL
-		),
-		last_24_runs AS (
-			SELECT
-				check_id,
-				json_agg(
-					json_build_object(
-						'id', id::text,
-						'timestamp', created_at,
-						'total_time_ms',
-						CASE
-							WHEN request_started_at IS NOT NULL AND response_ended_at IS NOT NULL
-								AND response_ended_at > request_started_at
-							THEN EXTRACT(EPOCH FROM (response_ended_at - request_started_at)) * 1000
-							ELSE 0
-						END,
-						'status', status
-					) ORDER BY created_at ASC, id ASC
-				) as runs
-			FROM ranked_runs
-			WHERE row_num <= 24
-			GROUP BY check_id
-		),
-		uptime_stats AS (
-			SELECT
-				check_id,
-				-- 24h uptime
-				CASE
-					WHEN COUNT(*) FILTER (WHERE created_at >= ? AND created_at <= ?) > 0
-					THEN (
-						COUNT(*) FILTER (
-							WHERE created_at >= ? AND created_at <= ?
-							AND status IN ('passing', 'degraded')
-						)::float /
-						NULLIF(COUNT(*) FILTER (WHERE created_at >= ? AND created_at <= ?), 0) * 100.0
-					)
-					ELSE NULL
-				END as uptime_24h,
-				-- 7d uptime
-				CASE
-					WHEN COUNT(*) FILTER (WHERE created_at >= ? AND created_at <= ?) > 0
-					THEN (
-						COUNT(*) FILTER (
-							WHERE created_at >= ? AND created_at <= ?
-							AND status IN ('passing', 'degraded')
-						)::float /
-						NULLIF(COUNT(*) FILTER (WHERE created_at >= ? AND created_at <= ?), 0) * 100.0
-					)
-					ELSE NULL
-				END as uptime_7d
-			FROM check_runs
-			WHERE deleted_at IS NULL
-			GROUP BY check_id
-		),
-		response_time_stats AS (
-			SELECT
-				check_id,
-				AVG(EXTRACT(EPOCH FROM (response_ended_at - request_started_at)) * 1000) as avg_response_time_24h,
-				PERCENTILE_CONT(0.95) WITHIN GROUP (
-					ORDER BY EXTRACT(EPOCH FROM (response_ended_at - request_started_at)) * 1000
-				) as p95_response_time_24h
-			FROM check_runs
-			WHERE deleted_at IS NULL
-				AND created_at >= ? AND created_at <= ?
-				AND request_started_at IS NOT NULL
-				AND response_ended_at IS NOT NULL
-				AND response_ended_at > request_started_at
-			GROUP BY check_id
-		)
 		SELECT
-			cb.id AS id,
-			cb.type AS type,
-			cb.name AS name,
-			cb.interval AS interval,
-			cb.last_run_at AS last_run_at,
-			cb.last_status AS last_status,
-			COALESCE(l24r.runs::text, '[]') AS last_24_runs,
-			us.uptime_24h AS uptime_24h,
-			us.uptime_7d AS uptime_7d,
-			rts.avg_response_time_24h AS avg_response_time_24h,
-			rts.p95_response_time_24h AS p95_response_time_24h
-		FROM checks_base cb
-		LEFT JOIN last_24_runs l24r ON cb.id = l24r.check_id
-		LEFT JOIN uptime_stats us ON cb.id = us.check_id
-		LEFT JOIN response_time_stats rts ON cb.id = rts.check_id
-		ORDER BY cb.name
-	`, projectID,
-		twentyFourHoursAgo, now, // 24h uptime filters (4 times)
-		twentyFourHoursAgo, now,
-		twentyFourHoursAgo, now,
-		sevenDaysAgo, now, // 7d uptime filters (4 times)
-		sevenDaysAgo, now,
-		sevenDaysAgo, now,
-		twentyFourHoursAgo, now, // response time stats filter
-	).Scan(&rows).Error
+			c.id,
+			c.type,
+			c.name,
+			c.interval,
+			c.last_run_at,
+			c.last_status,
+			cs.recent_runs,
+			cs.hourly_buckets
+		FROM checks c
+		LEFT JOIN check_stats cs ON cs.check_id = c.id
+		WHERE c.project_id = ? AND c.deleted_at IS NULL
+		ORDER BY c.name
+	`, projectID).Scan(&rows).Error
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list checks"})
 		return
 	}
 
-	// Parse JSON array for last_24_runs and left-pad to 24 items
+	// Left-pad last_24_runs to 24 items and compute the rolling windows
 	responses := make([]CheckListResponse, len(rows))
 	for i, row := range rows {
-		// Temporary struct for parsing JSON from SQL
-		type RunSummaryRaw struct {
-			ID          *string    `json:"id"`
-			Timestamp   *time.Time `json:"timestamp"`
-			TotalTimeMs *float64   `json:"total_time_ms"` // SQL returns float, convert to int
-			Status      *string    `json:"status"`
-		}
-
-		var last24RunsRaw []RunSummaryRaw
-		if row.Last24Runs != "" && row.Last24Runs != "[]" {
-			// Parse JSON array
-			if err := json.Unmarshal([]byte(row.Last24Runs), &last24RunsRaw); err != nil {
-				last24RunsRaw = []RunSummaryRaw{}
-			}
-		}
-
-		// Convert to RunSummary with proper UUID parsing and float to int conversion
-		last24Runs := make([]RunSummary, len(last24RunsRaw))
-		for j, raw := range last24RunsRaw {
-			var id *uuid.UUID
-			if raw.ID != nil {
-				if parsedID, err := uuid.Parse(*raw.ID); err == nil {
-					id = &parsedID
-				}
-			}
-
-			// Convert float to int (round down)
-			var totalTimeMs *int
-			if raw.TotalTimeMs != nil {
-				ms := int(math.Floor(*raw.TotalTimeMs))
-				totalTimeMs = &ms
-			}
-
+		recentRuns := row.Runs()
+		last24Runs := make([]RunSummary, len(recentRuns))
+		for j, run := range recentRuns {
+			id, timestamp, totalTimeMs, status := run.ID, run.Timestamp, run.TotalTimeMs, string(run.Status)
 			last24Runs[j] = RunSummary{
-				ID:          id,
-				Timestamp:   raw.Timestamp,
-				TotalTimeMs: totalTimeMs,
-				Status:      raw.Status,
+				ID:          &id,
+				Timestamp:   &timestamp,
+				TotalTimeMs: &totalTimeMs,
+				Status:      &status,
 			}
 		}
 
@@ -560,20 +630,20 @@ func (h *CheckHandler) ListChecks(c *gin.Context) {
 		// Format percentages and response times as strings with 0 decimal places (rounded down)
 		var uptime24hStr, uptime7dStr, avgMsStr, p95MsStr *string
 
-		if row.Uptime24h != nil {
-			val := fmt.Sprintf("%.0f", math.Floor(*row.Uptime24h))
+		if uptime24h := row.Uptime(24*time.Hour, now); uptime24h != nil {
+			val := fmt.Sprintf("%.0f", math.Floor(*uptime24h))
 			uptime24hStr = &val
 		}
-		if row.Uptime7d != nil {
-			val := fmt.Sprintf("%.0f", math.Floor(*row.Uptime7d))
+		if uptime7d := row.Uptime(models.StatsUptimeWindow, now); uptime7d != nil {
+			val := fmt.Sprintf("%.0f", math.Floor(*uptime7d))
 			uptime7dStr = &val
 		}
-		if row.AvgResponseTime24h != nil {
-			val := fmt.Sprintf("%.0f", math.Floor(*row.AvgResponseTime24h))
+		if avgMs := row.AvgLatencyMs(now); avgMs != nil {
+			val := fmt.Sprintf("%.0f", math.Floor(*avgMs))
 			avgMsStr = &val
 		}
-		if row.P95ResponseTime24h != nil {
-			val := fmt.Sprintf("%.0f", math.Floor(*row.P95ResponseTime24h))
+		if p95Ms := row.LatencyPercentileMs(0.95, now); p95Ms != nil {
+			val := fmt.Sprintf("%.0f", math.Floor(*p95Ms))
 			p95MsStr = &val
 		}
 
@@ -643,13 +713,18 @@ func (h *CheckHandler) UpdateCheck(c *gin.Context) {
 		FollowRedirects       *bool          `json:"follow_redirects"`
 		PlaywrightScript      *string        `json:"playwright_script,omitempty"`
 		Assertions            datatypes.JSON `json:"assertions"`
+		Steps                 datatypes.JSON `json:"steps,omitempty"`
 		PreScript             *string        `json:"pre_script,omitempty"`
 		PostScript            *string        `json:"post_script,omitempty"`
 		Interval              string         `json:"interval"`
+		Cron                  *string        `json:"cron,omitempty"`
+		Timezone              *string        `json:"timezone,omitempty"`
 		DegradedThreshold     *int           `json:"degraded_threshold"`
 		DegradedThresholdUnit *string        `json:"degraded_threshold_unit"`
 		FailedThreshold       *int           `json:"failed_threshold"`
 		FailedThresholdUnit   *string        `json:"failed_threshold_unit"`
+		CertDegradedDays      *int           `json:"cert_expiry_degraded_days,omitempty"`
+		CertFailedDays        *int           `json:"cert_expiry_failed_days,omitempty"`
 		Retries               *string        `json:"retries"`
 		RetriesCount          *int           `json:"retries_count,omitempty"`
 		RetriesDelay          *int           `json:"retries_delay,omitempty"`
@@ -664,10 +739,14 @@ func (h *CheckHandler) UpdateCheck(c *gin.Context) {
 		IsEnabled             *bool          `json:"is_enabled"`
 		IsMuted               *bool          `json:"is_muted"`
 		ShouldFail            *bool          `json:"should_fail"`
+		AlertFailureCount     *int           `json:"alert_failure_threshold,omitempty"`
+		AlertRecoveryCount    *int           `json:"alert_recovery_threshold,omitempty"`
+		AlertRegionQuorum     *int           `json:"alert_region_quorum,omitempty"`
 		DNSRecordType         *string        `json:"dns_record_type,omitempty"`
 		DNSResolver           *string        `json:"dns_resolver,omitempty"`
 		DNSResolverPort       *int           `json:"dns_resolver_port,omitempty"`
 		DNSResolverProtocol   *string        `json:"dns_resolver_protocol,omitempty"`
+		HeartbeatGrace        *string        `json:"heartbeat_grace,omitempty"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -680,8 +759,7 @@ func (h *CheckHandler) UpdateCheck(c *gin.Context) {
 		check.Name = req.Name
 	}
 	if req.Type != "" {
-		checkType := models.CheckType(req.Type)
-		check.Type = checkType
+		check.Type = models.CheckType(req.Type)
 	}
 	if req.Host != "" {
 		check.Host = req.Host
@@ -728,6 +806,9 @@ func (h *CheckHandler) UpdateCheck(c *gin.Context) {
 	if req.Assertions != nil {
 		check.Assertions = req.Assertions
 	}
+	if req.Steps != nil {
+		check.Steps = req.Steps
+	}
 	if req.PreScript != nil {
 		check.PreScript = req.PreScript
 	}
@@ -737,6 +818,16 @@ func (h *CheckHandler) UpdateCheck(c *gin.Context) {
 	if req.Interval != "" {
 		check.Interval = req.Interval
 	}
+	if req.Cron != nil {
+		// An empty expression switches the check back to its interval
+		check.Cron = req.Cron
+		if *req.Cron == "" {
+			check.Cron = nil
+		}
+	}
+	if req.Timezone != nil {
+		check.Timezone = *req.Timezone
+	}
 	if req.DegradedThreshold != nil {
 		check.DegradedThreshold = *req.DegradedThreshold
 	}
@@ -791,6 +882,19 @@ func (h *CheckHandler) UpdateCheck(c *gin.Context) {
 	if req.ShouldFail != nil {
 		check.ShouldFail = *req.ShouldFail
 	}
+	if msg := validateAlertPolicy(req.AlertFailureCount, req.AlertRecoveryCount, req.AlertRegionQuorum, len(check.Regions)); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	if req.AlertFailureCount != nil {
+		check.AlertFailureThreshold = *req.AlertFailureCount
+	}
+	if req.AlertRecoveryCount != nil {
+		check.AlertRecoveryThreshold = *req.AlertRecoveryCount
+	}
+	if req.AlertRegionQuorum != nil {
+		check.AlertRegionQuorum = *req.AlertRegionQuorum
+	}
 	if req.DNSRecordType != nil {
 		check.DNSRecordType = (*models.DNSRecordType)(req.DNSRecordType)
 	}
@@ -803,6 +907,41 @@ func (h *CheckHandler) UpdateCheck(c *gin.Context) {
 	if req.DNSResolverProtocol != nil {
 		check.DNSResolverProtocol = (*models.DNSResolverProtocolType)(req.DNSResolverProtocol)
 	}
+	if req.HeartbeatGrace != nil {
+		check.HeartbeatGrace = *req.HeartbeatGrace
+	}
+	if req.CertDegradedDays != nil {
+		check.CertExpiryDegradedDays = req.CertDegradedDays
+	}
+	if req.CertFailedDays != nil {
+		check.CertExpiryFailedDays = req.CertFailedDays
+	}
+	if msg := validateSchedule(check); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	if msg := validateCertExpiry(check); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	if err := checker.ValidateCheck(check); err != nil {
+		if errors.Is(err, checker.ErrUnknownCheckType) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid check type"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if msg := validateScripts(check); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	if check.Type == models.CheckTypeHeartbeat {
+		if msg := setupHeartbeat(check); msg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+	}
 
 	if err := h.store.UpdateCheck(check); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update check"})
@@ -909,3 +1048,78 @@ func (h *CheckHandler) GetCheckCountsByStatus(c *gin.Context) {
 
 	c.JSON(http.StatusOK, result)
 }
+
+// validateAlertPolicy checks the alert policy fields of a create or update request
+// and returns an error message, or an empty string if they are valid
+func validateAlertPolicy(failureThreshold, recoveryThreshold, regionQuorum *int, regionCount int) string {
+	if failureThreshold != nil && *failureThreshold < 1 {
+		return "alert_failure_threshold must be at least 1"
+	}
+	if recoveryThreshold != nil && *recoveryThreshold < 1 {
+		return "alert_recovery_threshold must be at least 1"
+	}
+	if regionQuorum != nil && (*regionQuorum < 1 || *regionQuorum > regionCount) {
+		return "alert_region_quorum must be between 1 and the number of regions"
+	}
+	return ""
+}
+
+// setupHeartbeat assigns a heartbeat check its ping token if it doesn't have one
+// yet. It returns an error message, or an empty string on success.
+func setupHeartbeat(check *models.Check) string {
+	if check.HeartbeatToken == nil {
+		token, err := generateToken()
+		if err != nil {
+			return "Failed to generate heartbeat token"
+		}
+		check.HeartbeatToken = &token
+	}
+	return ""
+}
+
+// validateScripts compiles the check's pre- and post-scripts. It returns an error
+// message, or an empty string if both are valid.
+func validateScripts(check *models.Check) string {
+	if check.PreScript != nil && *check.PreScript != "" {
+		if err := checker.ValidateScript(*check.PreScript); err != nil {
+			return "Invalid pre_script: " + err.Error()
+		}
+	}
+	if check.PostScript != nil && *check.PostScript != "" {
+		if err := checker.ValidateScript(*check.PostScript); err != nil {
+			return "Invalid post_script: " + err.Error()
+		}
+	}
+	return ""
+}
+
+// validateCertExpiry checks the certificate expiry thresholds. It returns an error
+// message, or an empty string if they are valid.
+func validateCertExpiry(check *models.Check) string {
+	degraded, failed := check.CertExpiryDegradedDays, check.CertExpiryFailedDays
+	if degraded != nil && *degraded < 0 {
+		return "cert_expiry_degraded_days must not be negative"
+	}
+	if failed != nil && *failed < 0 {
+		return "cert_expiry_failed_days must not be negative"
+	}
+	if degraded != nil && failed != nil && *failed > *degraded {
+		return "cert_expiry_failed_days must not exceed cert_expiry_degraded_days"
+	}
+	return ""
+}
+
+// validateSchedule checks the interval, cron expression and timezone of a check. It
+// returns an error message, or an empty string if they are valid.
+func validateSchedule(check *models.Check) string {
+	if check.IntervalDuration() <= 0 {
+		return "Invalid interval"
+	}
+	if _, err := check.Location(); err != nil {
+		return "Invalid timezone"
+	}
+	if _, err := check.CronSchedule(); err != nil {
+		return "Invalid cron: " + err.Error()
+	}
+	return ""
+}
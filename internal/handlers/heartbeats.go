@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"pulse/internal/checker"
+	"pulse/internal/heartbeat"
+	"pulse/internal/store"
+)
+
+// maxPingBodySize limits how much of a ping's body (e.g. job output) is stored with the run
+const maxPingBodySize = 10 * 1024
+
+type HeartbeatHandler struct {
+	store   *store.Store
+	monitor *heartbeat.Monitor
+}
+
+func NewHeartbeatHandler(s *store.Store, m *heartbeat.Monitor) *HeartbeatHandler {
+	return &HeartbeatHandler{store: s, monitor: m}
+}
+
+// Ping handles GET|POST /ping/:token and /ping/:token/:signal
+// The signal is "start", "fail" or the job's exit code, where 0 is a success.
+// Ping URLs are unauthenticated; the token identifies the check.
+func (h *HeartbeatHandler) Ping(c *gin.Context) {
+	ping := checker.Ping{
+		Kind:       checker.PingSuccess,
+		Method:     c.Request.Method,
+		RemoteAddr: c.ClientIP(),
+		UserAgent:  c.Request.UserAgent(),
+		ReceivedAt: time.Now().UTC(),
+	}
+
+	switch signal := c.Param("signal"); signal {
+	case "":
+	case "start":
+		ping.Kind = checker.PingStart
+	case "fail":
+		ping.Kind = checker.PingFail
+	default:
+		code, err := strconv.Atoi(signal)
+		if err != nil || code < 0 || code > 255 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ping signal"})
+			return
+		}
+		ping.ExitCode = &code
+		if code != 0 {
+			ping.Kind = checker.PingFail
+		}
+	}
+
+	if c.Request.Body != nil {
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxPingBodySize))
+		if err == nil {
+			ping.Body = string(body)
+		}
+	}
+
+	check, err := h.store.GetCheckByHeartbeatToken(c.Param("token"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Check not found"})
+		return
+	}
+
+	if !check.IsEnabled {
+		c.JSON(http.StatusOK, gin.H{"status": "ignored"})
+		return
+	}
+
+	if _, err := h.monitor.RecordPing(check, ping); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record ping"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
@@ -0,0 +1,307 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"pulse/internal/middleware"
+	"pulse/internal/models"
+)
+
+// IncidentResponse wraps an Incident with computed fields
+type IncidentResponse struct {
+	models.Incident
+	DurationSeconds int64 `json:"duration_seconds"` // Time from start to resolution, or to now if unresolved
+}
+
+func toIncidentResponse(incident *models.Incident) IncidentResponse {
+	return IncidentResponse{
+		Incident:        *incident,
+		DurationSeconds: int64(incident.Duration(time.Now().UTC()).Seconds()),
+	}
+}
+
+func toIncidentResponses(incidents []models.Incident) []IncidentResponse {
+	responses := make([]IncidentResponse, 0, len(incidents))
+	for i := range incidents {
+		responses = append(responses, toIncidentResponse(&incidents[i]))
+	}
+	return responses
+}
+
+// ListIncidents handles GET /projects/:projectId/incidents
+// Supports an optional status filter (open, acknowledged, resolved)
+func (h *AlertHandler) ListIncidents(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	projectID, err := uuid.Parse(c.Param("projectId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	isMember, err := h.store.IsProjectMember(projectID, userID)
+	if err != nil || !isMember {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+
+	var status *models.IncidentStatus
+	if value := c.Query("status"); value != "" {
+		s := models.IncidentStatus(value)
+		if s != models.IncidentStatusOpen && s != models.IncidentStatusAcknowledged && s != models.IncidentStatusResolved {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
+			return
+		}
+		status = &s
+	}
+
+	incidents, err := h.store.GetIncidentsByProject(projectID, status, 100)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list incidents"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": toIncidentResponses(incidents)})
+}
+
+// ListCheckIncidents handles GET /projects/:projectId/checks/:checkId/incidents
+// Returns the last 10 incidents for a check
+func (h *AlertHandler) ListCheckIncidents(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	projectID, err := uuid.Parse(c.Param("projectId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	isMember, err := h.store.IsProjectMember(projectID, userID)
+	if err != nil || !isMember {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+
+	checkID, err := uuid.Parse(c.Param("checkId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid check ID"})
+		return
+	}
+
+	incidents, err := h.store.GetIncidentsByCheck(checkID, 10)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list incidents"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": toIncidentResponses(incidents)})
+}
+
+// GetIncident handles GET /projects/:projectId/incidents/:incidentId
+func (h *AlertHandler) GetIncident(c *gin.Context) {
+	_, incident, ok := h.loadIncident(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, toIncidentResponse(incident))
+}
+
+// AcknowledgeIncident handles POST /projects/:projectId/incidents/:incidentId/acknowledge
+func (h *AlertHandler) AcknowledgeIncident(c *gin.Context) {
+	userID, incident, ok := h.loadIncident(c)
+	if !ok {
+		return
+	}
+
+	if incident.Status != models.IncidentStatusOpen {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only open incidents can be acknowledged"})
+		return
+	}
+
+	h.saveIncident(c, incident, map[string]interface{}{
+		"status":             models.IncidentStatusAcknowledged,
+		"acknowledged_at":    time.Now().UTC(),
+		"acknowledged_by_id": userID,
+	}, &models.IncidentEvent{
+		Type:   models.IncidentEventAcknowledged,
+		UserID: &userID,
+	})
+}
+
+// ResolveIncident handles POST /projects/:projectId/incidents/:incidentId/resolve
+func (h *AlertHandler) ResolveIncident(c *gin.Context) {
+	userID, incident, ok := h.loadIncident(c)
+	if !ok {
+		return
+	}
+
+	if incident.Status == models.IncidentStatusResolved {
+		c.JSON(http.StatusConflict, gin.H{"error": "Incident is already resolved"})
+		return
+	}
+
+	var req struct {
+		Message string `json:"message"`
+	}
+	// Body is optional
+	_ = c.ShouldBindJSON(&req)
+
+	h.saveIncident(c, incident, map[string]interface{}{
+		"status":         models.IncidentStatusResolved,
+		"resolved_at":    time.Now().UTC(),
+		"resolved_by_id": userID,
+	}, &models.IncidentEvent{
+		Type:    models.IncidentEventResolved,
+		Message: req.Message,
+		UserID:  &userID,
+	})
+}
+
+// AssignIncident handles PUT /projects/:projectId/incidents/:incidentId/assignee
+// A null user_id unassigns the incident
+func (h *AlertHandler) AssignIncident(c *gin.Context) {
+	userID, incident, ok := h.loadIncident(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		UserID *uuid.UUID `json:"user_id"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	message := "Unassigned"
+	if req.UserID != nil {
+		isMember, err := h.store.IsProjectMember(incident.ProjectID, *req.UserID)
+		if err != nil || !isMember {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Assignee must be a project member"})
+			return
+		}
+		assignee, err := h.store.GetUserByID(*req.UserID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Assignee not found"})
+			return
+		}
+		message = "Assigned to " + assignee.Name
+	}
+
+	h.saveIncident(c, incident, map[string]interface{}{
+		"assignee_id": req.UserID,
+	}, &models.IncidentEvent{
+		Type:    models.IncidentEventAssigned,
+		Message: message,
+		UserID:  &userID,
+	})
+}
+
+// AddIncidentNote handles POST /projects/:projectId/incidents/:incidentId/notes
+func (h *AlertHandler) AddIncidentNote(c *gin.Context) {
+	userID, incident, ok := h.loadIncident(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Message string `json:"message" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	event := &models.IncidentEvent{
+		Type:       models.IncidentEventNote,
+		Message:    req.Message,
+		IncidentID: incident.ID,
+		UserID:     &userID,
+	}
+
+	if err := h.store.CreateIncidentEvent(event); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add note"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, event)
+}
+
+// loadIncident authorizes the request and loads the incident from the path,
+// writing the error response itself when it fails
+func (h *AlertHandler) loadIncident(c *gin.Context) (uuid.UUID, *models.Incident, bool) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return uuid.Nil, nil, false
+	}
+
+	projectID, err := uuid.Parse(c.Param("projectId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return uuid.Nil, nil, false
+	}
+
+	isMember, err := h.store.IsProjectMember(projectID, userID)
+	if err != nil || !isMember {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return uuid.Nil, nil, false
+	}
+
+	incidentID, err := uuid.Parse(c.Param("incidentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid incident ID"})
+		return uuid.Nil, nil, false
+	}
+
+	incident, err := h.store.GetIncident(incidentID)
+	if err != nil || incident.ProjectID != projectID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Incident not found"})
+		return uuid.Nil, nil, false
+	}
+
+	return userID, incident, true
+}
+
+// saveIncident persists the columns an incident action changes with its timeline
+// event and responds with the reloaded incident. Only unresolved incidents are
+// changed, so an action racing a resolution doesn't reopen the incident.
+func (h *AlertHandler) saveIncident(c *gin.Context, incident *models.Incident, updates map[string]interface{}, event *models.IncidentEvent) {
+	updated, err := h.store.UpdateOpenIncident(incident.ID, updates)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update incident"})
+		return
+	}
+	if !updated {
+		c.JSON(http.StatusConflict, gin.H{"error": "Incident is already resolved"})
+		return
+	}
+
+	event.IncidentID = incident.ID
+	if err := h.store.CreateIncidentEvent(event); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update incident timeline"})
+		return
+	}
+
+	incident, err = h.store.GetIncident(incident.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load incident"})
+		return
+	}
+
+	c.JSON(http.StatusOK, toIncidentResponse(incident))
+}
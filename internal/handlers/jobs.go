@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"pulse/internal/middleware"
+	"pulse/internal/redis"
+	"pulse/internal/store"
+)
+
+type JobHandler struct {
+	store *store.Store
+	redis *redis.Client
+}
+
+func NewJobHandler(s *store.Store, r *redis.Client) *JobHandler {
+	return &JobHandler{store: s, redis: r}
+}
+
+// ListDeadJobs handles GET /projects/:projectId/dead-jobs
+func (h *JobHandler) ListDeadJobs(c *gin.Context) {
+	// Dead jobs are kept in Redis, which the API can run without
+	if h.redis == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Redis is required for dead jobs"})
+		return
+	}
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	projectID, err := uuid.Parse(c.Param("projectId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	isMember, err := h.store.IsProjectMember(projectID, userID)
+	if err != nil || !isMember {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+
+	jobs, err := h.projectDeadJobs(projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list dead jobs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, jobs)
+}
+
+// ReplayDeadJob handles POST /projects/:projectId/dead-jobs/:jobId/replay
+func (h *JobHandler) ReplayDeadJob(c *gin.Context) {
+	if h.redis == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Redis is required for dead jobs"})
+		return
+	}
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	projectID, err := uuid.Parse(c.Param("projectId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	isMember, err := h.store.IsProjectMember(projectID, userID)
+	if err != nil || !isMember {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+
+	jobID, err := uuid.Parse(c.Param("jobId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID"})
+		return
+	}
+
+	jobs, err := h.projectDeadJobs(projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list dead jobs"})
+		return
+	}
+
+	for _, dead := range jobs {
+		if dead.ID != jobID {
+			continue
+		}
+
+		job, err := h.redis.ReplayDeadJob(dead.RegionCode, jobID)
+		if errors.Is(err, redis.ErrJobNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Dead job not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to replay dead job"})
+			return
+		}
+
+		c.JSON(http.StatusOK, job)
+		return
+	}
+
+	c.JSON(http.StatusNotFound, gin.H{"error": "Dead job not found"})
+}
+
+// projectDeadJobs collects the dead jobs of a project across all regions
+func (h *JobHandler) projectDeadJobs(projectID uuid.UUID) ([]redis.DeadJob, error) {
+	regions, err := h.store.ListRegions()
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]redis.DeadJob, 0)
+	for _, region := range regions {
+		regionJobs, err := h.redis.ListDeadJobs(region.Code)
+		if err != nil {
+			return nil, err
+		}
+		for _, job := range regionJobs {
+			if job.ProjectID == projectID {
+				jobs = append(jobs, job)
+			}
+		}
+	}
+
+	return jobs, nil
+}
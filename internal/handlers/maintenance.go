@@ -0,0 +1,285 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"pulse/internal/middleware"
+	"pulse/internal/models"
+	"pulse/internal/store"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type MaintenanceHandler struct {
+	store *store.Store
+}
+
+func NewMaintenanceHandler(s *store.Store) *MaintenanceHandler {
+	return &MaintenanceHandler{store: s}
+}
+
+// CreateMaintenanceWindow handles POST /projects/:projectId/maintenance-windows
+func (h *MaintenanceHandler) CreateMaintenanceWindow(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	projectID, err := uuid.Parse(c.Param("projectId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	isMember, err := h.store.IsProjectMember(projectID, userID)
+	if err != nil || !isMember {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+
+	var req struct {
+		Name        string      `json:"name" binding:"required"`
+		Description *string     `json:"description,omitempty"`
+		StartsAt    time.Time   `json:"starts_at" binding:"required"`
+		EndsAt      time.Time   `json:"ends_at" binding:"required"`
+		RRule       *string     `json:"rrule,omitempty"`
+		Timezone    string      `json:"timezone"`
+		CheckIDs    []uuid.UUID `json:"check_ids,omitempty"`
+		TagIDs      []uuid.UUID `json:"tag_ids,omitempty"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	window := &models.MaintenanceWindow{
+		Name:        req.Name,
+		Description: req.Description,
+		StartsAt:    req.StartsAt,
+		EndsAt:      req.EndsAt,
+		RRule:       req.RRule,
+		Timezone:    req.Timezone,
+		ProjectID:   projectID,
+	}
+	if window.Timezone == "" {
+		window.Timezone = "UTC"
+	}
+
+	if msg := validateMaintenanceWindow(window); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	if err := h.store.CreateMaintenanceWindow(window, req.CheckIDs, req.TagIDs); err != nil {
+		if errors.Is(err, store.ErrMaintenanceTargetNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Check or tag not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create maintenance window"})
+		return
+	}
+
+	window, err = h.store.GetMaintenanceWindow(window.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load maintenance window"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, window)
+}
+
+// ListMaintenanceWindows handles GET /projects/:projectId/maintenance-windows
+func (h *MaintenanceHandler) ListMaintenanceWindows(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	projectID, err := uuid.Parse(c.Param("projectId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	isMember, err := h.store.IsProjectMember(projectID, userID)
+	if err != nil || !isMember {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+
+	windows, err := h.store.GetMaintenanceWindowsByProject(projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list maintenance windows"})
+		return
+	}
+
+	c.JSON(http.StatusOK, windows)
+}
+
+// GetMaintenanceWindow handles GET /projects/:projectId/maintenance-windows/:windowId
+func (h *MaintenanceHandler) GetMaintenanceWindow(c *gin.Context) {
+	window, ok := h.loadMaintenanceWindow(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, window)
+}
+
+// UpdateMaintenanceWindow handles PUT /projects/:projectId/maintenance-windows/:windowId
+func (h *MaintenanceHandler) UpdateMaintenanceWindow(c *gin.Context) {
+	window, ok := h.loadMaintenanceWindow(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Name        *string      `json:"name"`
+		Description *string      `json:"description"`
+		StartsAt    *time.Time   `json:"starts_at"`
+		EndsAt      *time.Time   `json:"ends_at"`
+		RRule       *string      `json:"rrule"`
+		Timezone    *string      `json:"timezone"`
+		CheckIDs    *[]uuid.UUID `json:"check_ids"`
+		TagIDs      *[]uuid.UUID `json:"tag_ids"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Name != nil {
+		window.Name = *req.Name
+	}
+	if req.Description != nil {
+		window.Description = req.Description
+	}
+	if req.StartsAt != nil {
+		window.StartsAt = *req.StartsAt
+	}
+	if req.EndsAt != nil {
+		window.EndsAt = *req.EndsAt
+	}
+	if req.RRule != nil {
+		// An empty rule turns a recurring window into a one-off window
+		if *req.RRule == "" {
+			window.RRule = nil
+		} else {
+			window.RRule = req.RRule
+		}
+	}
+	if req.Timezone != nil {
+		window.Timezone = *req.Timezone
+	}
+
+	if msg := validateMaintenanceWindow(window); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	// Targets that aren't given are kept
+	checkIDs := make([]uuid.UUID, 0, len(window.Checks))
+	for _, check := range window.Checks {
+		checkIDs = append(checkIDs, check.ID)
+	}
+	if req.CheckIDs != nil {
+		checkIDs = *req.CheckIDs
+	}
+
+	tagIDs := make([]uuid.UUID, 0, len(window.Tags))
+	for _, tag := range window.Tags {
+		tagIDs = append(tagIDs, tag.ID)
+	}
+	if req.TagIDs != nil {
+		tagIDs = *req.TagIDs
+	}
+
+	if err := h.store.UpdateMaintenanceWindow(window, checkIDs, tagIDs); err != nil {
+		if errors.Is(err, store.ErrMaintenanceTargetNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Check or tag not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update maintenance window"})
+		return
+	}
+
+	window, err := h.store.GetMaintenanceWindow(window.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load maintenance window"})
+		return
+	}
+
+	c.JSON(http.StatusOK, window)
+}
+
+// DeleteMaintenanceWindow handles DELETE /projects/:projectId/maintenance-windows/:windowId
+func (h *MaintenanceHandler) DeleteMaintenanceWindow(c *gin.Context) {
+	window, ok := h.loadMaintenanceWindow(c)
+	if !ok {
+		return
+	}
+
+	if err := h.store.DeleteMaintenanceWindow(window.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete maintenance window"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Maintenance window deleted"})
+}
+
+// loadMaintenanceWindow authorizes the request and loads the window from the path,
+// writing the error response itself when it fails
+func (h *MaintenanceHandler) loadMaintenanceWindow(c *gin.Context) (*models.MaintenanceWindow, bool) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return nil, false
+	}
+
+	projectID, err := uuid.Parse(c.Param("projectId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return nil, false
+	}
+
+	isMember, err := h.store.IsProjectMember(projectID, userID)
+	if err != nil || !isMember {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return nil, false
+	}
+
+	windowID, err := uuid.Parse(c.Param("windowId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid maintenance window ID"})
+		return nil, false
+	}
+
+	window, err := h.store.GetMaintenanceWindow(windowID)
+	if err != nil || window.ProjectID != projectID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Maintenance window not found"})
+		return nil, false
+	}
+
+	return window, true
+}
+
+// validateMaintenanceWindow returns an error message, or an empty string if the window is valid
+func validateMaintenanceWindow(window *models.MaintenanceWindow) string {
+	if !window.EndsAt.After(window.StartsAt) {
+		return "ends_at must be after starts_at"
+	}
+	if _, err := time.LoadLocation(window.Timezone); err != nil {
+		return "Invalid timezone"
+	}
+	if _, err := window.Recurrence(); err != nil {
+		return "Invalid rrule: " + err.Error()
+	}
+	return ""
+}
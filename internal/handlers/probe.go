@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"gorm.io/gorm"
+
+	"pulse/internal/checker"
+	"pulse/internal/middleware"
+	"pulse/internal/models"
+	"pulse/internal/store"
+)
+
+// probeLabels label every probe metric with the check and region of the run. Tags
+// are joined by commas in name order.
+var probeLabels = []string{"project", "check_id", "check", "type", "region", "tags"}
+
+// probePhases maps the durations of a run's network timings to phase labels
+var probePhases = map[string]string{
+	"dns_duration_us":     "dns",
+	"tcp_duration_us":     "connect",
+	"tls_duration_us":     "tls",
+	"request_duration_us": "request",
+	"ttfb_us":             "processing",
+	"download_us":         "transfer",
+}
+
+type ProbeHandler struct {
+	store *store.Store
+}
+
+func NewProbeHandler(s *store.Store) *ProbeHandler {
+	return &ProbeHandler{store: s}
+}
+
+// Metrics handles GET /probe/metrics
+// It exposes the latest run of each check of a project per region in the Prometheus
+// text format, like the blackbox exporter. Scrapes authenticate with the project's
+// metrics token as bearer token.
+func (h *ProbeHandler) Metrics(c *gin.Context) {
+	token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "authorization header required"})
+		return
+	}
+
+	project, err := h.store.GetProjectByMetricsToken(token)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid metrics token"})
+		return
+	}
+
+	checks, err := h.store.GetChecksByProject(project.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list checks"})
+		return
+	}
+	runs, err := h.store.GetLatestCheckRunsByProject(project.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list check runs"})
+		return
+	}
+
+	registry := prometheus.NewRegistry()
+	collector := newProbeCollector(project, checks, runs)
+	registry.MustRegister(collector)
+
+	promhttp.HandlerFor(registry, promhttp.HandlerOpts{}).ServeHTTP(c.Writer, c.Request)
+}
+
+// RotateMetricsToken handles POST /projects/:projectId/metrics-token
+// It generates a new metrics token, replacing the previous one, and returns it.
+func (h *ProbeHandler) RotateMetricsToken(c *gin.Context) {
+	projectID, ok := h.requireProjectAdmin(c)
+	if !ok {
+		return
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate metrics token"})
+		return
+	}
+	if err := h.store.SetProjectMetricsToken(projectID, &token); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update metrics token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"token": token})
+}
+
+// DeleteMetricsToken handles DELETE /projects/:projectId/metrics-token
+func (h *ProbeHandler) DeleteMetricsToken(c *gin.Context) {
+	projectID, ok := h.requireProjectAdmin(c)
+	if !ok {
+		return
+	}
+
+	if err := h.store.SetProjectMetricsToken(projectID, nil); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update metrics token"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// requireProjectAdmin parses the project of the request and checks that the user
+// administers it. It writes the error response and returns false otherwise.
+func (h *ProbeHandler) requireProjectAdmin(c *gin.Context) (uuid.UUID, bool) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return uuid.Nil, false
+	}
+
+	projectID, err := uuid.Parse(c.Param("projectId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return uuid.Nil, false
+	}
+
+	if _, err := h.store.GetProject(projectID); errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		return uuid.Nil, false
+	}
+
+	isAdmin, err := h.store.IsProjectAdmin(projectID, userID)
+	if err != nil || !isAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only project admins can manage the metrics token"})
+		return uuid.Nil, false
+	}
+
+	return projectID, true
+}
+
+var (
+	probeSuccessDesc = prometheus.NewDesc("probe_success",
+		"Whether the latest run passed or was degraded.", probeLabels, nil)
+	probeDegradedDesc = prometheus.NewDesc("probe_degraded",
+		"Whether the latest run was degraded.", probeLabels, nil)
+	probeDurationDesc = prometheus.NewDesc("probe_duration_seconds",
+		"Duration of the latest run, retries included.", probeLabels, nil)
+	probePhaseDesc = prometheus.NewDesc("probe_phase_duration_seconds",
+		"Duration of each network phase of the latest run.", append(probeLabels, "phase"), nil)
+	probeStatusCodeDesc = prometheus.NewDesc("probe_http_status_code",
+		"Response status code of the latest run.", probeLabels, nil)
+	probeCertExpiryDesc = prometheus.NewDesc("probe_ssl_earliest_cert_expiry",
+		"Unix time the first certificate of the latest run's chain expires.", probeLabels, nil)
+	probeTimestampDesc = prometheus.NewDesc("probe_timestamp_seconds",
+		"Unix time the latest run started.", probeLabels, nil)
+)
+
+// probeCollector exposes the latest runs of a project's checks
+type probeCollector struct {
+	project *models.Project
+	checks  map[uuid.UUID]*models.Check
+	runs    []models.CheckRun
+}
+
+func newProbeCollector(project *models.Project, checks []models.Check, runs []models.CheckRun) *probeCollector {
+	byID := make(map[uuid.UUID]*models.Check, len(checks))
+	for i := range checks {
+		byID[checks[i].ID] = &checks[i]
+	}
+	return &probeCollector{project: project, checks: byID, runs: runs}
+}
+
+func (pc *probeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- probeSuccessDesc
+	ch <- probeDegradedDesc
+	ch <- probeDurationDesc
+	ch <- probePhaseDesc
+	ch <- probeStatusCodeDesc
+	ch <- probeCertExpiryDesc
+	ch <- probeTimestampDesc
+}
+
+func (pc *probeCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, run := range pc.runs {
+		check, ok := pc.checks[run.CheckID]
+		if !ok {
+			continue
+		}
+		labels := pc.labels(check, &run)
+
+		success, degraded := 0.0, 0.0
+		switch run.Status {
+		case models.CheckRunStatusPassing:
+			success = 1
+		case models.CheckRunStatusDegraded:
+			success, degraded = 1, 1
+		}
+		ch <- prometheus.MustNewConstMetric(probeSuccessDesc, prometheus.GaugeValue, success, labels...)
+		ch <- prometheus.MustNewConstMetric(probeDegradedDesc, prometheus.GaugeValue, degraded, labels...)
+		ch <- prometheus.MustNewConstMetric(probeDurationDesc, prometheus.GaugeValue, run.RunEndedAt.Sub(run.RunStartedAt).Seconds(), labels...)
+		ch <- prometheus.MustNewConstMetric(probeTimestampDesc, prometheus.GaugeValue, float64(run.RunStartedAt.Unix()), labels...)
+
+		if run.ResponseStatusCode != nil {
+			ch <- prometheus.MustNewConstMetric(probeStatusCodeDesc, prometheus.GaugeValue, float64(*run.ResponseStatusCode), labels...)
+		}
+
+		var timings map[string]interface{}
+		if err := json.Unmarshal(run.NetworkTimings, &timings); err == nil {
+			for key, phase := range probePhases {
+				if us, ok := timings[key].(float64); ok {
+					seconds := (time.Duration(us) * time.Microsecond).Seconds()
+					ch <- prometheus.MustNewConstMetric(probePhaseDesc, prometheus.GaugeValue, seconds, append(labels, phase)...)
+				}
+			}
+		}
+
+		if expiry, ok := certExpiry(run.Response); ok {
+			ch <- prometheus.MustNewConstMetric(probeCertExpiryDesc, prometheus.GaugeValue, float64(expiry.Unix()), labels...)
+		}
+	}
+}
+
+// labels returns the probeLabels values of a run
+func (pc *probeCollector) labels(check *models.Check, run *models.CheckRun) []string {
+	tags := make([]string, 0, len(check.Tags))
+	for _, tag := range check.Tags {
+		tags = append(tags, tag.Name)
+	}
+	sort.Strings(tags)
+
+	return []string{
+		pc.project.Name,
+		check.ID.String(),
+		check.Name,
+		string(check.Type),
+		run.Region.Code,
+		strings.Join(tags, ","),
+	}
+}
+
+// certExpiry returns when the earliest certificate of a run's TLS chain expires
+func certExpiry(response []byte) (time.Time, bool) {
+	var data struct {
+		TLS *checker.TLSInfo `json:"tls"`
+	}
+	if err := json.Unmarshal(response, &data); err != nil || data.TLS == nil {
+		return time.Time{}, false
+	}
+
+	var earliest time.Time
+	for _, cert := range data.TLS.Chain {
+		if earliest.IsZero() || cert.NotAfter.Before(earliest) {
+			earliest = cert.NotAfter
+		}
+	}
+	return earliest, !earliest.IsZero()
+}
@@ -133,7 +133,9 @@ func (h *ProjectHandler) UpdateProject(c *gin.Context) {
 	}
 
 	var req struct {
-		Name string `json:"name" binding:"required"`
+		Name                string `json:"name" binding:"required"`
+		RunRetentionDays    *int   `json:"run_retention_days" binding:"omitempty,min=1"`
+		RollupRetentionDays *int   `json:"rollup_retention_days" binding:"omitempty,min=1"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -142,6 +144,17 @@ func (h *ProjectHandler) UpdateProject(c *gin.Context) {
 	}
 
 	project.Name = req.Name
+	if req.RunRetentionDays != nil {
+		project.RunRetentionDays = *req.RunRetentionDays
+	}
+	if req.RollupRetentionDays != nil {
+		project.RollupRetentionDays = *req.RollupRetentionDays
+	}
+	if project.RollupRetentionDays < project.RunRetentionDays {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "rollup_retention_days must be at least run_retention_days"})
+		return
+	}
+
 	if err := h.store.UpdateProject(project); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update project"})
 		return
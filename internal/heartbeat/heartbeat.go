@@ -0,0 +1,151 @@
+package heartbeat
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"pulse/internal/alerter"
+	"pulse/internal/checker"
+	"pulse/internal/clickhouse"
+	"pulse/internal/models"
+	"pulse/internal/store"
+)
+
+// ErrNoRegion is returned when a heartbeat check has no region to record runs in
+var ErrNoRegion = errors.New("heartbeat check has no regions configured")
+
+// Monitor records heartbeat pings and missed pings as check runs
+type Monitor struct {
+	store   *store.Store
+	alerter *alerter.Alerter
+	runs    *clickhouse.Writer
+}
+
+// New creates a heartbeat monitor. a may be nil, in which case runs are recorded
+// without alerting. runs may be nil when ClickHouse isn't configured.
+func New(s *store.Store, a *alerter.Alerter, runs *clickhouse.Writer) *Monitor {
+	return &Monitor{
+		store:   s,
+		alerter: a,
+		runs:    runs,
+	}
+}
+
+// RecordPing handles a call to the check's ping URL. A start ping only marks the
+// job as running and moves the deadline to the end of its grace period; any other
+// ping records a run and pushes the deadline forward.
+func (m *Monitor) RecordPing(check *models.Check, ping checker.Ping) (*models.CheckRun, error) {
+	if ping.Kind == checker.PingStart {
+		region := check.PrimaryRegion()
+		if region == nil {
+			return nil, ErrNoRegion
+		}
+		if err := m.store.StartHeartbeat(check.ID, ping.ReceivedAt); err != nil {
+			return nil, err
+		}
+		// A job that hangs after starting is caught once its grace period is up,
+		// rather than at the deadline of its last ping
+		return nil, m.store.ScheduleCheckRegion(check.ID, region.ID, ping.ReceivedAt.Add(check.HeartbeatGraceDuration()))
+	}
+
+	result := checker.HeartbeatPingResult(check, ping)
+	run, err := m.record(check, result, result.RequestStartedAt, ping.ReceivedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.store.RecordHeartbeatPing(check.ID, ping.ReceivedAt); err != nil {
+		return run, err
+	}
+
+	nextRun := ping.ReceivedAt.Add(check.IntervalDuration() + check.HeartbeatGraceDuration())
+	if err := m.store.UpdateCheckRegionStatus(check.ID, run.RegionID, &nextRun, result.Status); err != nil {
+		return run, err
+	}
+
+	return run, nil
+}
+
+// CheckMissed evaluates a due heartbeat check and records a run if its ping is
+// overdue. Overdue checks are re-evaluated every interval until a ping arrives,
+// checks that were pinged in the meantime are rescheduled to their deadline.
+func (m *Monitor) CheckMissed(check *models.Check) (*models.CheckRun, error) {
+	now := time.Now().UTC()
+
+	region := check.PrimaryRegion()
+	if region == nil {
+		return nil, ErrNoRegion
+	}
+
+	if deadline := check.HeartbeatDeadline(); !now.After(deadline) {
+		return nil, m.store.ScheduleCheckRegion(check.ID, region.ID, deadline)
+	}
+
+	result := checker.EvaluateHeartbeat(check)
+	run, err := m.record(check, result, now, now)
+	if err != nil {
+		return nil, err
+	}
+
+	nextRun := now.Add(check.IntervalDuration())
+	if err := m.store.UpdateCheckRegionStatus(check.ID, region.ID, &nextRun, result.Status); err != nil {
+		return run, err
+	}
+
+	return run, nil
+}
+
+// record saves a heartbeat run in the check's primary region and processes alerts
+func (m *Monitor) record(check *models.Check, result checker.Result, startedAt, endedAt time.Time) (*models.CheckRun, error) {
+	region := check.PrimaryRegion()
+	if region == nil {
+		return nil, ErrNoRegion
+	}
+
+	run := &models.CheckRun{
+		Status:        result.Status,
+		FailureReason: result.FailureReason,
+		Inverted:      result.Inverted,
+
+		RunStartedAt: startedAt,
+		RunEndedAt:   endedAt,
+
+		RequestStartedAt: result.RequestStartedAt,
+		FirstByteAt:      result.FirstByteAt,
+		ResponseEndedAt:  result.ResponseEndedAt,
+
+		IPAddress:         result.IPAddress,
+		ResponseSizeBytes: result.ResponseSizeBytes,
+
+		AssertionResults: result.AssertionResults,
+		PlaywrightReport: result.PlaywrightReport,
+		NetworkTimings:   result.NetworkTimings,
+		Response:         result.Response,
+
+		RegionID: region.ID,
+		CheckID:  check.ID,
+	}
+
+	// Runs during maintenance are recorded but excluded from alerts and uptime
+	inMaintenance, err := m.store.IsCheckInMaintenance(check.ID, check.ProjectID, endedAt)
+	if err != nil {
+		log.Printf("Error checking maintenance windows for %s: %v", check.ID, err)
+	}
+	run.InMaintenance = inMaintenance
+
+	createdRun, err := m.store.CreateCheckRun(run)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.runs != nil {
+		m.runs.Write(clickhouse.NewCheckRun(createdRun, check, region.Code))
+	}
+
+	if m.alerter != nil {
+		m.alerter.ProcessCheckResult(check, createdRun)
+	}
+
+	return createdRun, nil
+}
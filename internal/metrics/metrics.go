@@ -1,60 +1,150 @@
 package metrics
 
 import (
-	"sync/atomic"
+	"math"
+	"net/http"
+	"strconv"
 	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "pulse"
+
+var (
+	checksExecuted = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "checks_executed_total",
+		Help:      "Check runs executed by workers.",
+	}, []string{"type", "region"})
+
+	checksFailed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "checks_failed_total",
+		Help:      "Check runs that didn't pass.",
+	}, []string{"type", "region"})
+
+	runDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "check_run_duration_seconds",
+		Help:      "Duration of check runs, retries included.",
+		Buckets:   []float64{.01, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 120},
+	}, []string{"type", "region"})
+
+	activeJobs = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "active_jobs",
+		Help:      "Jobs currently being processed by workers.",
+	})
+
+	workers = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "workers",
+		Help:      "Size of the worker pool.",
+	}, []string{"region"})
+
+	pollLag = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "scheduler_poll_lag_seconds",
+		Help:      "Time between a check becoming due and the scheduler claiming it.",
+		Buckets:   []float64{.5, 1, 2.5, 5, 10, 15, 30, 60, 120, 300},
+	}, []string{"region"})
+
+	enqueueFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "scheduler_enqueue_failures_total",
+		Help:      "Due checks the scheduler failed to enqueue.",
+	}, []string{"region"})
+
+	alertsSent = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "alerts_sent_total",
+		Help:      "Notifications delivered to channels.",
+	}, []string{"channel_type"})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of HTTP requests by route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
 )
 
-// Metrics tracks system-wide metrics using atomic operations for thread-safety
-type Metrics struct {
-	ChecksExecuted int64
-	ChecksFailed   int64
-	AlertsSent     int64
-	ActiveJobs     int64
-	StartTime      time.Time
+// ObserveCheckRun records an executed run of a check type in a region
+func ObserveCheckRun(checkType, region string, duration time.Duration, passed bool) {
+	checksExecuted.WithLabelValues(checkType, region).Inc()
+	runDuration.WithLabelValues(checkType, region).Observe(duration.Seconds())
+	if !passed {
+		checksFailed.WithLabelValues(checkType, region).Inc()
+	}
 }
 
-var globalMetrics = &Metrics{
-	StartTime: time.Now().UTC(),
+// IncrementActiveJobs increments the active jobs gauge
+func IncrementActiveJobs() {
+	activeJobs.Inc()
 }
 
-// GetMetrics returns the current metrics snapshot
-func GetMetrics() *Metrics {
-	return &Metrics{
-		ChecksExecuted: atomic.LoadInt64(&globalMetrics.ChecksExecuted),
-		ChecksFailed:   atomic.LoadInt64(&globalMetrics.ChecksFailed),
-		AlertsSent:     atomic.LoadInt64(&globalMetrics.AlertsSent),
-		ActiveJobs:     atomic.LoadInt64(&globalMetrics.ActiveJobs),
-		StartTime:      globalMetrics.StartTime,
-	}
+// DecrementActiveJobs decrements the active jobs gauge
+func DecrementActiveJobs() {
+	activeJobs.Dec()
 }
 
-// IncrementChecksExecuted increments the checks executed counter
-func IncrementChecksExecuted() {
-	atomic.AddInt64(&globalMetrics.ChecksExecuted, 1)
+// SetWorkers records the size of the worker pool of a region
+func SetWorkers(region string, n int) {
+	workers.WithLabelValues(region).Set(float64(n))
 }
 
-// IncrementChecksFailed increments the checks failed counter
-func IncrementChecksFailed() {
-	atomic.AddInt64(&globalMetrics.ChecksFailed, 1)
+// ObservePollLag records how late the scheduler claimed a due check
+func ObservePollLag(region string, lag time.Duration) {
+	pollLag.WithLabelValues(region).Observe(lag.Seconds())
 }
 
-// IncrementAlertsSent increments the alerts sent counter
-func IncrementAlertsSent() {
-	atomic.AddInt64(&globalMetrics.AlertsSent, 1)
+// IncrementEnqueueFailures counts a due check the scheduler failed to enqueue
+func IncrementEnqueueFailures(region string) {
+	enqueueFailures.WithLabelValues(region).Inc()
 }
 
-// IncrementActiveJobs increments the active jobs counter
-func IncrementActiveJobs() {
-	atomic.AddInt64(&globalMetrics.ActiveJobs, 1)
+// IncrementAlertsSent counts a notification delivered to a channel
+func IncrementAlertsSent(channelType string) {
+	alertsSent.WithLabelValues(channelType).Inc()
 }
 
-// DecrementActiveJobs decrements the active jobs counter
-func DecrementActiveJobs() {
-	atomic.AddInt64(&globalMetrics.ActiveJobs, -1)
+// RegisterQueueDepth exposes the depth of a region's job queue, read by depth
+// on each scrape.
+func RegisterQueueDepth(region string, depth func() (int64, error)) {
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   namespace,
+		Name:        "queue_depth",
+		Help:        "Jobs waiting in the queue of a region.",
+		ConstLabels: prometheus.Labels{"region": region},
+	}, func() float64 {
+		n, err := depth()
+		if err != nil {
+			return math.NaN()
+		}
+		return float64(n)
+	})
+}
+
+// Middleware records the latency of requests by route. Requests that matched no
+// route are grouped, so unknown paths don't grow the label set.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		httpRequestDuration.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Observe(time.Since(start).Seconds())
+	}
 }
 
-// GetUptime returns the uptime in seconds
-func GetUptime() int64 {
-	return int64(time.Since(globalMetrics.StartTime).Seconds())
+// Handler serves the metrics in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.Handler()
 }
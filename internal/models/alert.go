@@ -24,6 +24,8 @@ type Alert struct {
 	CheckID uuid.UUID `gorm:"type:uuid;index;not null" json:"check_id"`
 	Check   Check     `gorm:"foreignKey:CheckID" json:"check,omitempty"`
 
+	IncidentID *uuid.UUID `gorm:"type:uuid;index" json:"incident_id,omitempty"`
+
 	CreatedAt time.Time      `gorm:"type:timestamptz;not null" json:"created_at"`
 	UpdatedAt time.Time      `gorm:"type:timestamptz;not null" json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"type:timestamptz;index" json:"-"`
@@ -35,16 +35,33 @@ type Check struct {
 	PlaywrightScript *string        `gorm:"type:text" json:"playwright_script,omitempty"`
 	Assertions       datatypes.JSON `gorm:"type:jsonb" json:"assertions"`
 
+	// Steps of a multi-step API check, run in order against Host. Values extracted
+	// from one step's response are referenced by later steps as {{name}}.
+	Steps datatypes.JSON `gorm:"type:jsonb" json:"steps,omitempty"`
+
+	// JavaScript run in a sandbox around the request of HTTP checks. The pre-script
+	// may change `request`, the post-script inspects `response` and calls assert().
 	PreScript  *string `gorm:"type:text" json:"pre_script,omitempty"`
 	PostScript *string `gorm:"type:text" json:"post_script,omitempty"`
 
 	Interval string `gorm:"not null;default:'10m'" json:"interval"`
 
+	// Cron runs the check at the times of a five-field cron expression, e.g.
+	// "0 9 * * 1-5" for weekdays at 09:00, evaluated in Timezone. Interval is used
+	// when it is unset.
+	Cron     *string `gorm:"type:varchar(100)" json:"cron,omitempty"`
+	Timezone string  `gorm:"type:varchar(64);not null;default:'UTC'" json:"timezone"`
+
 	DegradedThreshold     int      `gorm:"not null" json:"degraded_threshold"`
 	DegradedThresholdUnit UnitType `gorm:"type:varchar(2);default:'ms'" json:"degraded_threshold_unit"`
 	FailedThreshold       int      `gorm:"not null" json:"failed_threshold"`
 	FailedThresholdUnit   UnitType `gorm:"type:varchar(2);default:'ms'" json:"failed_threshold_unit"`
 
+	// Runs of TLS-enabled HTTP and TCP checks are degraded or failing when the peer
+	// certificate chain expires in fewer days than these thresholds.
+	CertExpiryDegradedDays *int `json:"cert_expiry_degraded_days,omitempty"`
+	CertExpiryFailedDays   *int `json:"cert_expiry_failed_days,omitempty"`
+
 	Retries             RetryType        `gorm:"type:varchar(20);default:'none'" json:"retries"`
 	RetriesCount        *int             `json:"retries_count,omitempty"`
 	RetriesDelay        *int             `json:"retries_delay,omitempty"`
@@ -62,6 +79,21 @@ type Check struct {
 	DNSResolverPort     *int                     `json:"dns_resolver_port,omitempty"`
 	DNSResolverProtocol *DNSResolverProtocolType `json:"dns_resolver_protocol,omitempty"`
 
+	// Heartbeat checks are pinged by the monitored job at /ping/:token instead of
+	// being probed. A run fails when no ping arrives within Interval plus the grace period.
+	HeartbeatToken     *string    `gorm:"type:varchar(64);uniqueIndex" json:"heartbeat_token,omitempty"`
+	HeartbeatGrace     string     `gorm:"type:varchar(20);not null;default:'5m'" json:"heartbeat_grace"`
+	HeartbeatStartedAt *time.Time `gorm:"type:timestamptz" json:"heartbeat_started_at,omitempty"` // set by /start, cleared by the next ping
+	LastPingAt         *time.Time `gorm:"type:timestamptz" json:"last_ping_at,omitempty"`
+
+	// Alert policy: a region counts as failing after AlertFailureThreshold consecutive
+	// non-passing runs and as recovered after AlertRecoveryThreshold consecutive passes.
+	// The check alerts once AlertRegionQuorum regions agree.
+	AlertFailureThreshold  int            `gorm:"not null;default:1" json:"alert_failure_threshold"`
+	AlertRecoveryThreshold int            `gorm:"not null;default:1" json:"alert_recovery_threshold"`
+	AlertRegionQuorum      int            `gorm:"not null;default:1" json:"alert_region_quorum"`
+	AlertStatus            CheckRunStatus `gorm:"type:varchar(20);default:'unknown'" json:"alert_status"` // last status alerted on
+
 	LastStatus CheckRunStatus `gorm:"type:varchar(20);default:'unknown'" json:"last_status"`
 	LastRunAt  *time.Time     `gorm:"type:timestamptz" json:"last_run_at,omitempty"`
 	NextRunAt  *time.Time     `gorm:"type:timestamptz" json:"next_run_at,omitempty"`
@@ -73,8 +105,13 @@ type Check struct {
 	ProjectID uuid.UUID `gorm:"type:uuid;index;not null" json:"project_id"`
 	Project   Project   `gorm:"foreignKey:ProjectID" json:"project,omitempty"`
 
-	Tags    []Tag    `gorm:"many2many:check_tags;" json:"tags,omitempty"`
-	Regions []Region `gorm:"many2many:check_regions;" json:"regions,omitempty"`
+	Tags     []Tag                 `gorm:"many2many:check_tags;" json:"tags,omitempty"`
+	Regions  []Region              `gorm:"many2many:check_regions;" json:"regions,omitempty"`
+	Channels []NotificationChannel `gorm:"many2many:check_channels;" json:"channels,omitempty"`
+
+	// Scheduling state of each region. NextRunAt, LastRunAt and LastStatus above
+	// summarize it: the earliest next run and the latest run of any region.
+	RegionStates []CheckRegionState `gorm:"foreignKey:CheckID" json:"region_states,omitempty"`
 }
 
 func (c *Check) FailedThresholdDuration() time.Duration {
@@ -133,3 +170,38 @@ func (c *Check) IntervalDuration() time.Duration {
 	}
 	return duration
 }
+
+func (c *Check) HeartbeatGraceDuration() time.Duration {
+	duration, err := time.ParseDuration(c.HeartbeatGrace)
+	if err != nil {
+		return 0
+	}
+	return duration
+}
+
+// HeartbeatDeadline returns the time by which the next heartbeat ping must arrive.
+// A job that signalled /start has the grace period to finish, otherwise the next
+// ping is expected one interval after the last one (or after creation).
+func (c *Check) HeartbeatDeadline() time.Time {
+	if c.HeartbeatStartedAt != nil && (c.LastPingAt == nil || c.HeartbeatStartedAt.After(*c.LastPingAt)) {
+		return c.HeartbeatStartedAt.Add(c.HeartbeatGraceDuration())
+	}
+
+	last := c.CreatedAt
+	if c.LastPingAt != nil {
+		last = *c.LastPingAt
+	}
+	return last.Add(c.IntervalDuration() + c.HeartbeatGraceDuration())
+}
+
+// PrimaryRegion returns the region that records runs which don't belong to a single
+// region, such as heartbeat pings. It is the oldest of the check's regions.
+func (c *Check) PrimaryRegion() *Region {
+	var primary *Region
+	for i := range c.Regions {
+		if primary == nil || c.Regions[i].ID.String() < primary.ID.String() {
+			primary = &c.Regions[i]
+		}
+	}
+	return primary
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CheckRegionState is the scheduling state of a check in one of its regions. Each
+// region runs the check on its own cadence, so run times and status are per region.
+type CheckRegionState struct {
+	CheckID  uuid.UUID `gorm:"type:uuid;primaryKey" json:"check_id"`
+	RegionID uuid.UUID `gorm:"type:uuid;primaryKey" json:"region_id"`
+	Region   *Region   `gorm:"foreignKey:RegionID" json:"region,omitempty"`
+
+	NextRunAt  *time.Time     `gorm:"type:timestamptz" json:"next_run_at,omitempty"`
+	LastRunAt  *time.Time     `gorm:"type:timestamptz" json:"last_run_at,omitempty"`
+	LastStatus CheckRunStatus `gorm:"type:varchar(20);not null;default:'unknown'" json:"last_status"`
+
+	UpdatedAt time.Time `gorm:"type:timestamptz;not null" json:"updated_at"`
+}
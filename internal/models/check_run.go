@@ -19,6 +19,7 @@ const (
 	FailureConnectionRefused  FailureReason = "connection_refused"
 	FailureNetworkUnreachable FailureReason = "network_unreachable"
 	FailureIPVersionMismatch  FailureReason = "ip_version_mismatch"
+	FailureCertExpiring       FailureReason = "certificate_expiring"
 
 	// Timeouts
 	FailureRequestTimeout  FailureReason = "request_timeout"
@@ -45,10 +46,17 @@ const (
 	FailureElementNotFound FailureReason = "element_not_found"
 	FailurePageCrash       FailureReason = "page_crash"
 
+	// Heartbeat
+	FailureHeartbeatMissed FailureReason = "heartbeat_missed"
+	FailureHeartbeatFailed FailureReason = "heartbeat_failed"
+
 	// Orchestration
 	FailureMaxRetries FailureReason = "max_retries_exceeded"
 	FailureDependency FailureReason = "dependency_failed"
 
+	// Expected failure
+	FailureUnexpectedSuccess FailureReason = "unexpected_success" // check with ShouldFail passed
+
 	// Internal
 	FailureAgent         FailureReason = "agent_error"
 	FailureSerialization FailureReason = "serialization_error"
@@ -62,6 +70,9 @@ type CheckRun struct {
 	FailureReason      *FailureReason `gorm:"type:varchar(50)" json:"failure_reason,omitempty"`
 	ResponseStatusCode *int32         `gorm:"type:integer" json:"response_status_code,omitempty"`
 
+	InMaintenance bool `gorm:"type:boolean;default:false" json:"in_maintenance"` // excluded from alerts and uptime
+	Inverted      bool `gorm:"type:boolean;default:false" json:"inverted"`       // verdict flipped by the check's ShouldFail
+
 	RunStartedAt time.Time `gorm:"type:timestamptz;not null" json:"run_started_at"`
 	RunEndedAt   time.Time `gorm:"type:timestamptz" json:"run_ended_at"`
 
@@ -78,6 +89,8 @@ type CheckRun struct {
 	PlaywrightReport datatypes.JSON `gorm:"type:jsonb" json:"playwright_report,omitempty"`
 	NetworkTimings   datatypes.JSON `gorm:"type:jsonb" json:"network_timings"`
 	Response         datatypes.JSON `gorm:"type:jsonb" json:"response,omitempty"`
+	StepResults      datatypes.JSON `gorm:"type:jsonb" json:"step_results,omitempty"` // timings and assertions of each step of multi-step checks
+	ScriptLogs       datatypes.JSON `gorm:"type:jsonb" json:"script_logs,omitempty"`  // console output and errors of pre- and post-scripts
 
 	RegionID uuid.UUID `gorm:"type:uuid;index;not null" json:"region_id"`
 	Region   Region    `gorm:"foreignKey:RegionID" json:"region,omitempty"`
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+type RollupResolution string
+
+const (
+	RollupResolutionHour RollupResolution = "hour"
+	RollupResolutionDay  RollupResolution = "day"
+)
+
+// CheckRunRollup aggregates the runs of a check in a region over an hour or a day.
+// Runs older than their project's retention are rolled up into an hourly and a daily
+// rollup before being deleted; hourly rollups are kept for the project's rollup
+// retention, daily rollups for as long as the check exists.
+type CheckRunRollup struct {
+	ID uuid.UUID `gorm:"type:uuid;primaryKey;default:uuidv7()" json:"id"`
+
+	CheckID    uuid.UUID        `gorm:"type:uuid;not null" json:"check_id"`
+	RegionID   uuid.UUID        `gorm:"type:uuid;not null" json:"region_id"`
+	Resolution RollupResolution `gorm:"type:varchar(10);not null" json:"resolution"`
+
+	BucketStart time.Time `gorm:"type:timestamptz;not null" json:"bucket_start"`
+
+	// Status counts exclude runs during maintenance, like uptime
+	TotalRuns int `gorm:"type:integer;not null;default:0" json:"total_runs"`
+	Passing   int `gorm:"type:integer;not null;default:0" json:"passing"`
+	Degraded  int `gorm:"type:integer;not null;default:0" json:"degraded"`
+	Failing   int `gorm:"type:integer;not null;default:0" json:"failing"`
+
+	ResponseTimeP50Us *int           `gorm:"type:integer" json:"response_time_p50_us,omitempty"`
+	ResponseTimeP95Us *int           `gorm:"type:integer" json:"response_time_p95_us,omitempty"`
+	ResponseTimeP99Us *int           `gorm:"type:integer" json:"response_time_p99_us,omitempty"`
+	NetworkTimings    datatypes.JSON `gorm:"type:jsonb" json:"network_timings"` // average duration of each network phase
+
+	CreatedAt time.Time `gorm:"type:timestamptz" json:"created_at"`
+	UpdatedAt time.Time `gorm:"type:timestamptz" json:"updated_at"`
+}
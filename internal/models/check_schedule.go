@@ -0,0 +1,92 @@
+package models
+
+import (
+	"hash/fnv"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	// defaultInterval is used when a check's interval can't be parsed
+	defaultInterval = 10 * time.Minute
+	// cronPhaseSpread bounds the phase offset of cron scheduled checks, so runs
+	// still start within the minute their expression names
+	cronPhaseSpread = time.Minute
+)
+
+// cronParser accepts standard five-field expressions and descriptors such as @daily
+var cronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// CronSchedule parses the check's cron expression. It returns nil for checks that
+// run every Interval.
+func (c *Check) CronSchedule() (cron.Schedule, error) {
+	if c.Cron == nil || *c.Cron == "" {
+		return nil, nil
+	}
+	return cronParser.Parse(*c.Cron)
+}
+
+// Location returns the timezone the check's cron expression is evaluated in
+func (c *Check) Location() (*time.Location, error) {
+	if c.Timezone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(c.Timezone)
+}
+
+// PhaseOffset returns the check's fixed offset within its schedule. It is derived
+// from the check ID, so checks with the same schedule are spread out instead of
+// all becoming due in the same poll, and each check keeps its offset across runs.
+func (c *Check) PhaseOffset() time.Duration {
+	spread := cronPhaseSpread
+	if c.Cron == nil || *c.Cron == "" {
+		spread = c.scheduleInterval()
+	}
+
+	h := fnv.New64a()
+	h.Write(c.ID[:])
+	return time.Duration(h.Sum64() % uint64(spread)).Truncate(time.Second)
+}
+
+// NextRunAfter returns the run that follows the one scheduled at scheduled. Runs fall
+// on fixed slots, the check's interval or cron times shifted by its phase offset,
+// so the cadence doesn't drift with poll latency or run duration. Slots that already
+// passed by now are skipped rather than caught up on.
+func (c *Check) NextRunAfter(scheduled, now time.Time) time.Time {
+	next := c.nextSlot(scheduled)
+	if !next.After(now) {
+		next = c.nextSlot(now)
+	}
+	return next
+}
+
+// nextSlot returns the first slot strictly after t
+func (c *Check) nextSlot(t time.Time) time.Time {
+	offset := c.PhaseOffset()
+
+	schedule, err := c.CronSchedule()
+	if err == nil && schedule != nil {
+		loc, err := c.Location()
+		if err != nil {
+			loc = time.UTC
+		}
+		next := schedule.Next(t.Add(-offset).In(loc))
+		if !next.IsZero() {
+			return next.Add(offset).UTC()
+		}
+	}
+
+	// Interval slots are aligned to the Unix epoch
+	interval := c.scheduleInterval()
+	slot := (t.UnixNano()-int64(offset))/int64(interval) + 1
+	return time.Unix(0, slot*int64(interval)+int64(offset)).UTC()
+}
+
+// scheduleInterval returns the check's interval, or the default if it is invalid
+func (c *Check) scheduleInterval() time.Duration {
+	if interval := c.IntervalDuration(); interval > 0 {
+		return interval
+	}
+	return defaultInterval
+}
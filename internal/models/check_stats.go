@@ -0,0 +1,245 @@
+package models
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+const (
+	// StatsRecentRuns is how many of the latest runs are kept for the sparkline
+	StatsRecentRuns = 24
+	// StatsUptimeWindow is the longest window uptime is computed over
+	StatsUptimeWindow = 7 * 24 * time.Hour
+	// StatsLatencyWindow is the window latencies are computed over
+	StatsLatencyWindow = 24 * time.Hour
+)
+
+// LatencyBoundsMs are the upper bounds of the latency histogram buckets, in
+// milliseconds. A run falls in the bucket of the number of bounds it reaches, so
+// bucket 0 holds latencies below the first bound and the last bucket those above
+// the last bound, like Postgres' width_bucket.
+var LatencyBoundsMs = []float64{
+	10, 25, 50, 75, 100, 150, 200, 300, 400, 500, 750,
+	1000, 1500, 2000, 3000, 5000, 7500, 10000, 15000, 30000, 60000,
+}
+
+// CheckStats holds the stats of a check's list entry. They are updated as each run
+// is stored, so listing checks doesn't aggregate their runs.
+type CheckStats struct {
+	CheckID uuid.UUID `gorm:"type:uuid;primaryKey" json:"check_id"`
+
+	RecentRuns    datatypes.JSON `gorm:"type:jsonb;not null;default:'[]'" json:"recent_runs"`    // []StatsRun, oldest first
+	HourlyBuckets datatypes.JSON `gorm:"type:jsonb;not null;default:'[]'" json:"hourly_buckets"` // []StatsBucket, oldest first
+
+	UpdatedAt time.Time `gorm:"type:timestamptz" json:"updated_at"`
+}
+
+func (CheckStats) TableName() string {
+	return "check_stats"
+}
+
+// StatsRun summarizes a run for the sparkline
+type StatsRun struct {
+	ID          uuid.UUID      `json:"id"`
+	Timestamp   time.Time      `json:"timestamp"`
+	TotalTimeMs int            `json:"total_time_ms"`
+	Status      CheckRunStatus `json:"status"`
+}
+
+// StatsBucket aggregates the runs of a check created within an hour. Runs during
+// maintenance count towards latency but not uptime. Histograms are dropped once the
+// hour leaves the latency window.
+type StatsBucket struct {
+	Start        int64       `json:"t"` // unix time the hour starts
+	Runs         int         `json:"n"`
+	Up           int         `json:"u"` // passing or degraded runs
+	LatencyCount int         `json:"c"`
+	LatencySumMs float64     `json:"s"`
+	Histogram    map[int]int `json:"h,omitempty"` // runs per latency bucket
+}
+
+// RunLatencyMs returns how long the run's request took in milliseconds, or false if
+// the run didn't get a response.
+func RunLatencyMs(run *CheckRun) (float64, bool) {
+	if run.RequestStartedAt.IsZero() || run.ResponseEndedAt.IsZero() || !run.ResponseEndedAt.After(run.RequestStartedAt) {
+		return 0, false
+	}
+	return float64(run.ResponseEndedAt.Sub(run.RequestStartedAt)) / float64(time.Millisecond), true
+}
+
+// latencyBucket returns the histogram bucket of a latency
+func latencyBucket(ms float64) int {
+	return sort.Search(len(LatencyBoundsMs), func(i int) bool { return LatencyBoundsMs[i] > ms })
+}
+
+// Runs returns the recent runs, oldest first
+func (s *CheckStats) Runs() []StatsRun {
+	var runs []StatsRun
+	if len(s.RecentRuns) > 0 {
+		_ = json.Unmarshal(s.RecentRuns, &runs)
+	}
+	return runs
+}
+
+// Buckets returns the hourly buckets, oldest first
+func (s *CheckStats) Buckets() []StatsBucket {
+	var buckets []StatsBucket
+	if len(s.HourlyBuckets) > 0 {
+		_ = json.Unmarshal(s.HourlyBuckets, &buckets)
+	}
+	return buckets
+}
+
+// Record adds a stored run to the stats. Buckets past the uptime window are
+// dropped, as of now.
+func (s *CheckStats) Record(run *CheckRun, now time.Time) error {
+	latency, hasLatency := RunLatencyMs(run)
+
+	runs := append(s.Runs(), StatsRun{
+		ID:          run.ID,
+		Timestamp:   run.CreatedAt,
+		TotalTimeMs: int(latency),
+		Status:      run.Status,
+	})
+	// Runs of a check's regions can be stored out of order
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].Timestamp.Before(runs[j].Timestamp) })
+	if len(runs) > StatsRecentRuns {
+		runs = runs[len(runs)-StatsRecentRuns:]
+	}
+
+	buckets := s.Buckets()
+	start := run.CreatedAt.UTC().Truncate(time.Hour).Unix()
+	i := sort.Search(len(buckets), func(i int) bool { return buckets[i].Start >= start })
+	if i == len(buckets) || buckets[i].Start != start {
+		buckets = append(buckets, StatsBucket{})
+		copy(buckets[i+1:], buckets[i:])
+		buckets[i] = StatsBucket{Start: start}
+	}
+
+	bucket := &buckets[i]
+	if !run.InMaintenance {
+		bucket.Runs++
+		if run.Status == CheckRunStatusPassing || run.Status == CheckRunStatusDegraded {
+			bucket.Up++
+		}
+	}
+	if hasLatency {
+		bucket.LatencyCount++
+		bucket.LatencySumMs += latency
+		if bucket.Histogram == nil {
+			bucket.Histogram = make(map[int]int)
+		}
+		bucket.Histogram[latencyBucket(latency)]++
+	}
+
+	kept := buckets[:0]
+	for _, b := range buckets {
+		end := time.Unix(b.Start, 0).Add(time.Hour)
+		if !end.After(now.Add(-StatsUptimeWindow)) {
+			continue
+		}
+		if !end.After(now.Add(-StatsLatencyWindow)) {
+			b.Histogram = nil
+		}
+		kept = append(kept, b)
+	}
+
+	recentRuns, err := json.Marshal(runs)
+	if err != nil {
+		return err
+	}
+	hourlyBuckets, err := json.Marshal(kept)
+	if err != nil {
+		return err
+	}
+	s.RecentRuns = recentRuns
+	s.HourlyBuckets = hourlyBuckets
+	return nil
+}
+
+// bucketsIn returns the buckets overlapping the window ending now. Windows are
+// rounded out to whole hours.
+func bucketsIn(buckets []StatsBucket, window time.Duration, now time.Time) []StatsBucket {
+	var in []StatsBucket
+	for _, b := range buckets {
+		if time.Unix(b.Start, 0).Add(time.Hour).After(now.Add(-window)) {
+			in = append(in, b)
+		}
+	}
+	return in
+}
+
+// Uptime returns the percentage of runs that were up within the window ending now,
+// or nil if there were none.
+func (s *CheckStats) Uptime(window time.Duration, now time.Time) *float64 {
+	var runs, up int
+	for _, b := range bucketsIn(s.Buckets(), window, now) {
+		runs += b.Runs
+		up += b.Up
+	}
+	if runs == 0 {
+		return nil
+	}
+	uptime := float64(up) / float64(runs) * 100.0
+	return &uptime
+}
+
+// AvgLatencyMs returns the average latency of the runs within the latency window
+// ending now, or nil if there were none.
+func (s *CheckStats) AvgLatencyMs(now time.Time) *float64 {
+	var count int
+	var sum float64
+	for _, b := range bucketsIn(s.Buckets(), StatsLatencyWindow, now) {
+		count += b.LatencyCount
+		sum += b.LatencySumMs
+	}
+	if count == 0 {
+		return nil
+	}
+	avg := sum / float64(count)
+	return &avg
+}
+
+// LatencyPercentileMs estimates the latency percentile p (0 to 1) of the runs within
+// the latency window ending now from their histogram, interpolating within the
+// bucket it falls in. It returns nil if there were no runs.
+func (s *CheckStats) LatencyPercentileMs(p float64, now time.Time) *float64 {
+	histogram := make([]int, len(LatencyBoundsMs)+1)
+	total := 0
+	for _, b := range bucketsIn(s.Buckets(), StatsLatencyWindow, now) {
+		for i, n := range b.Histogram {
+			if i >= 0 && i < len(histogram) {
+				histogram[i] += n
+				total += n
+			}
+		}
+	}
+	if total == 0 {
+		return nil
+	}
+
+	rank := p * float64(total)
+	seen := 0
+	for i, n := range histogram {
+		if n == 0 || float64(seen+n) < rank {
+			seen += n
+			continue
+		}
+		// The last bucket is unbounded, its latencies are reported as its lower bound
+		if i == len(LatencyBoundsMs) {
+			value := LatencyBoundsMs[i-1]
+			return &value
+		}
+		lower := 0.0
+		if i > 0 {
+			lower = LatencyBoundsMs[i-1]
+		}
+		value := lower + (LatencyBoundsMs[i]-lower)*(rank-float64(seen))/float64(n)
+		return &value
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Incident groups the alerts of a check from the first failing alert until recovery
+type Incident struct {
+	ID uuid.UUID `gorm:"type:uuid;primaryKey;default:uuidv7()" json:"id"`
+
+	Status   IncidentStatus `gorm:"type:varchar(20);not null;default:'open'" json:"status"`
+	Severity CheckRunStatus `gorm:"type:varchar(20);not null" json:"severity"` // worst alert status seen
+
+	StartedAt      time.Time  `gorm:"type:timestamptz;not null" json:"started_at"`
+	AcknowledgedAt *time.Time `gorm:"type:timestamptz" json:"acknowledged_at,omitempty"`
+	ResolvedAt     *time.Time `gorm:"type:timestamptz" json:"resolved_at,omitempty"`
+
+	AcknowledgedByID *uuid.UUID `gorm:"type:uuid" json:"acknowledged_by_id,omitempty"`
+	AcknowledgedBy   *User      `gorm:"foreignKey:AcknowledgedByID" json:"acknowledged_by,omitempty"`
+	ResolvedByID     *uuid.UUID `gorm:"type:uuid" json:"resolved_by_id,omitempty"` // nil when resolved by recovery
+	ResolvedBy       *User      `gorm:"foreignKey:ResolvedByID" json:"resolved_by,omitempty"`
+	AssigneeID       *uuid.UUID `gorm:"type:uuid;index" json:"assignee_id,omitempty"`
+	Assignee         *User      `gorm:"foreignKey:AssigneeID" json:"assignee,omitempty"`
+
+	ProjectID uuid.UUID `gorm:"type:uuid;index;not null" json:"project_id"`
+	Project   Project   `gorm:"foreignKey:ProjectID" json:"project,omitempty"`
+
+	CheckID uuid.UUID `gorm:"type:uuid;index;not null" json:"check_id"`
+	Check   *Check    `gorm:"foreignKey:CheckID" json:"check,omitempty"`
+
+	Alerts []Alert         `gorm:"foreignKey:IncidentID" json:"alerts,omitempty"`
+	Events []IncidentEvent `gorm:"foreignKey:IncidentID" json:"events,omitempty"`
+
+	CreatedAt time.Time      `gorm:"type:timestamptz;not null" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"type:timestamptz;not null" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"type:timestamptz;index" json:"-"`
+}
+
+// Duration returns how long the incident lasted, or has lasted so far if it is unresolved
+func (i *Incident) Duration(now time.Time) time.Duration {
+	if i.ResolvedAt != nil {
+		return i.ResolvedAt.Sub(i.StartedAt)
+	}
+	return now.Sub(i.StartedAt)
+}
+
+// IncidentEvent is an entry on an incident's timeline
+type IncidentEvent struct {
+	ID uuid.UUID `gorm:"type:uuid;primaryKey;default:uuidv7()" json:"id"`
+
+	Type    IncidentEventType `gorm:"type:varchar(20);not null" json:"type"`
+	Message string            `gorm:"type:text" json:"message,omitempty"`
+
+	IncidentID uuid.UUID  `gorm:"type:uuid;index;not null" json:"incident_id"`
+	AlertID    *uuid.UUID `gorm:"type:uuid" json:"alert_id,omitempty"`
+	Alert      *Alert     `gorm:"foreignKey:AlertID" json:"alert,omitempty"`
+	UserID     *uuid.UUID `gorm:"type:uuid" json:"user_id,omitempty"` // nil for system events
+	User       *User      `gorm:"foreignKey:UserID" json:"user,omitempty"`
+
+	CreatedAt time.Time      `gorm:"type:timestamptz;not null" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"type:timestamptz;not null" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"type:timestamptz;index" json:"-"`
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/teambition/rrule-go"
+	"gorm.io/gorm"
+)
+
+// MaintenanceWindow is a one-off or recurring period during which runs are recorded
+// but excluded from alerting and uptime. A window without checks or tags targets
+// every check of its project.
+type MaintenanceWindow struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey;default:uuidv7()" json:"id"`
+	Name        string    `gorm:"not null" json:"name"`
+	Description *string   `gorm:"type:text" json:"description,omitempty"`
+
+	StartsAt time.Time `gorm:"type:timestamptz;not null" json:"starts_at"` // start of the first occurrence
+	EndsAt   time.Time `gorm:"type:timestamptz;not null" json:"ends_at"`   // end of the first occurrence
+	RRule    *string   `gorm:"type:text" json:"rrule,omitempty"`           // RFC 5545 recurrence rule, e.g. FREQ=WEEKLY;BYDAY=SU
+	Timezone string    `gorm:"type:varchar(64);not null;default:'UTC'" json:"timezone"`
+
+	CreatedAt time.Time      `gorm:"type:timestamptz" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"type:timestamptz" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"type:timestamptz;index" json:"-"`
+
+	ProjectID uuid.UUID `gorm:"type:uuid;index;not null" json:"project_id"`
+	Project   Project   `gorm:"foreignKey:ProjectID" json:"project,omitempty"`
+
+	Checks []Check `gorm:"many2many:maintenance_window_checks;" json:"checks,omitempty"`
+	Tags   []Tag   `gorm:"many2many:maintenance_window_tags;" json:"tags,omitempty"`
+}
+
+// Duration returns the length of each occurrence of the window
+func (w *MaintenanceWindow) Duration() time.Duration {
+	return w.EndsAt.Sub(w.StartsAt)
+}
+
+// Recurrence parses the window's RRULE anchored at StartsAt in the window's timezone.
+// It returns nil for one-off windows.
+func (w *MaintenanceWindow) Recurrence() (*rrule.RRule, error) {
+	if w.RRule == nil || *w.RRule == "" {
+		return nil, nil
+	}
+
+	loc, err := time.LoadLocation(w.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	option, err := rrule.StrToROptionInLocation(*w.RRule, loc)
+	if err != nil {
+		return nil, err
+	}
+	option.Dtstart = w.StartsAt.In(loc)
+
+	return rrule.NewRRule(*option)
+}
+
+// IsActiveAt reports whether t falls inside an occurrence of the window
+func (w *MaintenanceWindow) IsActiveAt(t time.Time) bool {
+	rule, err := w.Recurrence()
+	if err != nil {
+		return false
+	}
+
+	if rule == nil {
+		return !t.Before(w.StartsAt) && t.Before(w.EndsAt)
+	}
+
+	start := rule.Before(t, true)
+	if start.IsZero() {
+		return false
+	}
+	return t.Before(start.Add(w.Duration()))
+}
+
+// NextOccurrence returns the start of the first occurrence after t, or false if there is none
+func (w *MaintenanceWindow) NextOccurrence(t time.Time) (time.Time, bool) {
+	rule, err := w.Recurrence()
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	if rule == nil {
+		if w.StartsAt.After(t) {
+			return w.StartsAt, true
+		}
+		return time.Time{}, false
+	}
+
+	next := rule.After(t, false)
+	return next, !next.IsZero()
+}
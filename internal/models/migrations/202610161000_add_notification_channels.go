@@ -0,0 +1,69 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterMigration(&gormigrate.Migration{
+		ID: "202610161000_add_notification_channels",
+		Migrate: func(tx *gorm.DB) error {
+			// Create notification_channels table
+			if err := tx.Exec(`
+				CREATE TABLE notification_channels (
+					id UUID PRIMARY KEY DEFAULT uuidv7(),
+					name VARCHAR NOT NULL,
+					type VARCHAR(20) NOT NULL,
+					config JSONB,
+					is_enabled BOOLEAN DEFAULT TRUE,
+					project_id UUID NOT NULL,
+					created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+					updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+					deleted_at TIMESTAMPTZ,
+					FOREIGN KEY (project_id) REFERENCES projects(id)
+				)
+			`).Error; err != nil {
+				return err
+			}
+
+			if err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_notification_channels_project_id ON notification_channels(project_id)`).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_notification_channels_deleted_at ON notification_channels(deleted_at)`).Error; err != nil {
+				return err
+			}
+
+			// Create check_channels join table
+			if err := tx.Exec(`
+				CREATE TABLE IF NOT EXISTS check_channels (
+					check_id UUID NOT NULL,
+					notification_channel_id UUID NOT NULL,
+					PRIMARY KEY (check_id, notification_channel_id),
+					FOREIGN KEY (check_id) REFERENCES checks(id) ON DELETE CASCADE,
+					FOREIGN KEY (notification_channel_id) REFERENCES notification_channels(id) ON DELETE CASCADE
+				)
+			`).Error; err != nil {
+				return err
+			}
+
+			if err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_check_channels_check_id ON check_channels(check_id)`).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_check_channels_notification_channel_id ON check_channels(notification_channel_id)`).Error; err != nil {
+				return err
+			}
+
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			if err := tx.Exec(`DROP TABLE IF EXISTS check_channels`).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec(`DROP TABLE IF EXISTS notification_channels`).Error; err != nil {
+				return err
+			}
+			return nil
+		},
+	})
+}
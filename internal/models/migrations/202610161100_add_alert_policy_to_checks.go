@@ -0,0 +1,45 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterMigration(&gormigrate.Migration{
+		ID: "202610161100_add_alert_policy_to_checks",
+		Migrate: func(tx *gorm.DB) error {
+			// Add alert policy columns
+			if err := tx.Exec(`
+				ALTER TABLE checks
+					ADD COLUMN alert_failure_threshold INTEGER NOT NULL DEFAULT 1,
+					ADD COLUMN alert_recovery_threshold INTEGER NOT NULL DEFAULT 1,
+					ADD COLUMN alert_region_quorum INTEGER NOT NULL DEFAULT 1,
+					ADD COLUMN alert_status VARCHAR(20) DEFAULT 'unknown'
+			`).Error; err != nil {
+				return err
+			}
+
+			// Seed the alerted status from the last known status so existing checks
+			// don't fire an alert on their next run
+			if err := tx.Exec("UPDATE checks SET alert_status = last_status").Error; err != nil {
+				return err
+			}
+
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			if err := tx.Exec(`
+				ALTER TABLE checks
+					DROP COLUMN alert_failure_threshold,
+					DROP COLUMN alert_recovery_threshold,
+					DROP COLUMN alert_region_quorum,
+					DROP COLUMN alert_status
+			`).Error; err != nil {
+				return err
+			}
+
+			return nil
+		},
+	})
+}
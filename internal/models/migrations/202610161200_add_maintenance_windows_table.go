@@ -0,0 +1,97 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterMigration(&gormigrate.Migration{
+		ID: "202610161200_add_maintenance_windows_table",
+		Migrate: func(tx *gorm.DB) error {
+			// Create maintenance_windows table
+			if err := tx.Exec(`
+				CREATE TABLE maintenance_windows (
+					id UUID PRIMARY KEY DEFAULT uuidv7(),
+					name VARCHAR NOT NULL,
+					description TEXT,
+					starts_at TIMESTAMPTZ NOT NULL,
+					ends_at TIMESTAMPTZ NOT NULL,
+					rrule TEXT,
+					timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+					project_id UUID NOT NULL,
+					created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+					updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+					deleted_at TIMESTAMPTZ,
+					FOREIGN KEY (project_id) REFERENCES projects(id),
+					CHECK (ends_at > starts_at)
+				)
+			`).Error; err != nil {
+				return err
+			}
+
+			if err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_maintenance_windows_project_id ON maintenance_windows(project_id)`).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_maintenance_windows_deleted_at ON maintenance_windows(deleted_at)`).Error; err != nil {
+				return err
+			}
+
+			// Create maintenance_window_checks join table
+			if err := tx.Exec(`
+				CREATE TABLE IF NOT EXISTS maintenance_window_checks (
+					maintenance_window_id UUID NOT NULL,
+					check_id UUID NOT NULL,
+					PRIMARY KEY (maintenance_window_id, check_id),
+					FOREIGN KEY (maintenance_window_id) REFERENCES maintenance_windows(id) ON DELETE CASCADE,
+					FOREIGN KEY (check_id) REFERENCES checks(id) ON DELETE CASCADE
+				)
+			`).Error; err != nil {
+				return err
+			}
+
+			if err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_maintenance_window_checks_check_id ON maintenance_window_checks(check_id)`).Error; err != nil {
+				return err
+			}
+
+			// Create maintenance_window_tags join table
+			if err := tx.Exec(`
+				CREATE TABLE IF NOT EXISTS maintenance_window_tags (
+					maintenance_window_id UUID NOT NULL,
+					tag_id UUID NOT NULL,
+					PRIMARY KEY (maintenance_window_id, tag_id),
+					FOREIGN KEY (maintenance_window_id) REFERENCES maintenance_windows(id) ON DELETE CASCADE,
+					FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+				)
+			`).Error; err != nil {
+				return err
+			}
+
+			if err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_maintenance_window_tags_tag_id ON maintenance_window_tags(tag_id)`).Error; err != nil {
+				return err
+			}
+
+			// Add in_maintenance column to check_runs
+			if err := tx.Exec("ALTER TABLE check_runs ADD COLUMN in_maintenance BOOLEAN NOT NULL DEFAULT false").Error; err != nil {
+				return err
+			}
+
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			if err := tx.Exec("ALTER TABLE check_runs DROP COLUMN in_maintenance").Error; err != nil {
+				return err
+			}
+			if err := tx.Exec(`DROP TABLE IF EXISTS maintenance_window_tags`).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec(`DROP TABLE IF EXISTS maintenance_window_checks`).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec(`DROP TABLE IF EXISTS maintenance_windows`).Error; err != nil {
+				return err
+			}
+			return nil
+		},
+	})
+}
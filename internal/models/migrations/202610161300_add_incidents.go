@@ -0,0 +1,107 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterMigration(&gormigrate.Migration{
+		ID: "202610161300_add_incidents",
+		Migrate: func(tx *gorm.DB) error {
+			// Create incidents table
+			if err := tx.Exec(`
+				CREATE TABLE incidents (
+					id UUID PRIMARY KEY DEFAULT uuidv7(),
+					status VARCHAR(20) NOT NULL DEFAULT 'open',
+					severity VARCHAR(20) NOT NULL,
+					started_at TIMESTAMPTZ NOT NULL,
+					acknowledged_at TIMESTAMPTZ,
+					resolved_at TIMESTAMPTZ,
+					acknowledged_by_id UUID,
+					resolved_by_id UUID,
+					assignee_id UUID,
+					project_id UUID NOT NULL,
+					check_id UUID NOT NULL,
+					created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+					updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+					deleted_at TIMESTAMPTZ,
+					FOREIGN KEY (acknowledged_by_id) REFERENCES users(id) ON DELETE SET NULL,
+					FOREIGN KEY (resolved_by_id) REFERENCES users(id) ON DELETE SET NULL,
+					FOREIGN KEY (assignee_id) REFERENCES users(id) ON DELETE SET NULL,
+					FOREIGN KEY (project_id) REFERENCES projects(id),
+					FOREIGN KEY (check_id) REFERENCES checks(id)
+				)
+			`).Error; err != nil {
+				return err
+			}
+
+			// Create indexes for incidents
+			if err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_incidents_project_id ON incidents(project_id)`).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_incidents_check_id ON incidents(check_id)`).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_incidents_assignee_id ON incidents(assignee_id)`).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_incidents_deleted_at ON incidents(deleted_at)`).Error; err != nil {
+				return err
+			}
+			// At most one unresolved incident per check
+			if err := tx.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_incidents_check_id_unresolved ON incidents(check_id) WHERE resolved_at IS NULL AND deleted_at IS NULL`).Error; err != nil {
+				return err
+			}
+
+			// Create incident_events table
+			if err := tx.Exec(`
+				CREATE TABLE incident_events (
+					id UUID PRIMARY KEY DEFAULT uuidv7(),
+					type VARCHAR(20) NOT NULL,
+					message TEXT,
+					incident_id UUID NOT NULL,
+					alert_id UUID,
+					user_id UUID,
+					created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+					updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+					deleted_at TIMESTAMPTZ,
+					FOREIGN KEY (incident_id) REFERENCES incidents(id) ON DELETE CASCADE,
+					FOREIGN KEY (alert_id) REFERENCES alerts(id),
+					FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL
+				)
+			`).Error; err != nil {
+				return err
+			}
+
+			if err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_incident_events_incident_id ON incident_events(incident_id)`).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_incident_events_deleted_at ON incident_events(deleted_at)`).Error; err != nil {
+				return err
+			}
+
+			// Link alerts to incidents
+			if err := tx.Exec(`ALTER TABLE alerts ADD COLUMN incident_id UUID REFERENCES incidents(id) ON DELETE SET NULL`).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_alerts_incident_id ON alerts(incident_id)`).Error; err != nil {
+				return err
+			}
+
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			if err := tx.Exec(`ALTER TABLE alerts DROP COLUMN incident_id`).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec(`DROP TABLE IF EXISTS incident_events`).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec(`DROP TABLE IF EXISTS incidents`).Error; err != nil {
+				return err
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,48 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterMigration(&gormigrate.Migration{
+		ID: "202610161400_add_heartbeat_to_checks",
+		Migrate: func(tx *gorm.DB) error {
+			// Add heartbeat columns
+			if err := tx.Exec(`
+				ALTER TABLE checks
+					ADD COLUMN heartbeat_token VARCHAR(64),
+					ADD COLUMN heartbeat_grace VARCHAR(20) NOT NULL DEFAULT '5m',
+					ADD COLUMN heartbeat_started_at TIMESTAMPTZ,
+					ADD COLUMN last_ping_at TIMESTAMPTZ
+			`).Error; err != nil {
+				return err
+			}
+
+			// Ping URLs resolve the check by token
+			if err := tx.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_checks_heartbeat_token ON checks(heartbeat_token)").Error; err != nil {
+				return err
+			}
+
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			if err := tx.Exec("DROP INDEX IF EXISTS idx_checks_heartbeat_token").Error; err != nil {
+				return err
+			}
+
+			if err := tx.Exec(`
+				ALTER TABLE checks
+					DROP COLUMN heartbeat_token,
+					DROP COLUMN heartbeat_grace,
+					DROP COLUMN heartbeat_started_at,
+					DROP COLUMN last_ping_at
+			`).Error; err != nil {
+				return err
+			}
+
+			return nil
+		},
+	})
+}
@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterMigration(&gormigrate.Migration{
+		ID: "202610161500_add_inverted_to_check_runs",
+		Migrate: func(tx *gorm.DB) error {
+			if err := tx.Exec("ALTER TABLE check_runs ADD COLUMN inverted BOOLEAN DEFAULT false").Error; err != nil {
+				return err
+			}
+
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			if err := tx.Exec("ALTER TABLE check_runs DROP COLUMN inverted").Error; err != nil {
+				return err
+			}
+
+			return nil
+		},
+	})
+}
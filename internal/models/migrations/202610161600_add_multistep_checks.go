@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterMigration(&gormigrate.Migration{
+		ID: "202610161600_add_multistep_checks",
+		Migrate: func(tx *gorm.DB) error {
+			if err := tx.Exec("ALTER TABLE checks ADD COLUMN steps JSONB").Error; err != nil {
+				return err
+			}
+
+			if err := tx.Exec("ALTER TABLE check_runs ADD COLUMN step_results JSONB").Error; err != nil {
+				return err
+			}
+
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			if err := tx.Exec("ALTER TABLE check_runs DROP COLUMN step_results").Error; err != nil {
+				return err
+			}
+
+			if err := tx.Exec("ALTER TABLE checks DROP COLUMN steps").Error; err != nil {
+				return err
+			}
+
+			return nil
+		},
+	})
+}
@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterMigration(&gormigrate.Migration{
+		ID: "202610161700_add_script_logs_to_check_runs",
+		Migrate: func(tx *gorm.DB) error {
+			if err := tx.Exec("ALTER TABLE check_runs ADD COLUMN script_logs JSONB").Error; err != nil {
+				return err
+			}
+
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			if err := tx.Exec("ALTER TABLE check_runs DROP COLUMN script_logs").Error; err != nil {
+				return err
+			}
+
+			return nil
+		},
+	})
+}
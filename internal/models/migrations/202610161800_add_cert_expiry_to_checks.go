@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterMigration(&gormigrate.Migration{
+		ID: "202610161800_add_cert_expiry_to_checks",
+		Migrate: func(tx *gorm.DB) error {
+			if err := tx.Exec("ALTER TABLE checks ADD COLUMN cert_expiry_degraded_days INTEGER").Error; err != nil {
+				return err
+			}
+
+			if err := tx.Exec("ALTER TABLE checks ADD COLUMN cert_expiry_failed_days INTEGER").Error; err != nil {
+				return err
+			}
+
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			if err := tx.Exec("ALTER TABLE checks DROP COLUMN cert_expiry_failed_days").Error; err != nil {
+				return err
+			}
+
+			if err := tx.Exec("ALTER TABLE checks DROP COLUMN cert_expiry_degraded_days").Error; err != nil {
+				return err
+			}
+
+			return nil
+		},
+	})
+}
@@ -0,0 +1,54 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterMigration(&gormigrate.Migration{
+		ID: "202610161900_add_check_region_states",
+		Migrate: func(tx *gorm.DB) error {
+			// Create check_region_states table
+			if err := tx.Exec(`
+				CREATE TABLE check_region_states (
+					check_id UUID NOT NULL,
+					region_id UUID NOT NULL,
+					next_run_at TIMESTAMPTZ,
+					last_run_at TIMESTAMPTZ,
+					last_status VARCHAR(20) NOT NULL DEFAULT 'unknown',
+					updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+					PRIMARY KEY (check_id, region_id),
+					FOREIGN KEY (check_id) REFERENCES checks(id) ON DELETE CASCADE,
+					FOREIGN KEY (region_id) REFERENCES regions(id) ON DELETE CASCADE
+				)
+			`).Error; err != nil {
+				return err
+			}
+
+			// Schedulers poll for the due checks of their region
+			if err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_check_region_states_region_next_run ON check_region_states(region_id, next_run_at)`).Error; err != nil {
+				return err
+			}
+
+			// Seed every region of a check with the check's current state
+			if err := tx.Exec(`
+				INSERT INTO check_region_states (check_id, region_id, next_run_at, last_run_at, last_status)
+				SELECT cr.check_id, cr.region_id, c.next_run_at, c.last_run_at, COALESCE(c.last_status, 'unknown')
+				FROM check_regions cr
+				JOIN checks c ON c.id = cr.check_id
+				ON CONFLICT DO NOTHING
+			`).Error; err != nil {
+				return err
+			}
+
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			if err := tx.Exec(`DROP TABLE IF EXISTS check_region_states`).Error; err != nil {
+				return err
+			}
+			return nil
+		},
+	})
+}
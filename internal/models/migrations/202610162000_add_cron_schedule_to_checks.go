@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterMigration(&gormigrate.Migration{
+		ID: "202610162000_add_cron_schedule_to_checks",
+		Migrate: func(tx *gorm.DB) error {
+			if err := tx.Exec("ALTER TABLE checks ADD COLUMN cron VARCHAR(100)").Error; err != nil {
+				return err
+			}
+
+			if err := tx.Exec("ALTER TABLE checks ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'UTC'").Error; err != nil {
+				return err
+			}
+
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			if err := tx.Exec("ALTER TABLE checks DROP COLUMN timezone").Error; err != nil {
+				return err
+			}
+
+			if err := tx.Exec("ALTER TABLE checks DROP COLUMN cron").Error; err != nil {
+				return err
+			}
+
+			return nil
+		},
+	})
+}
@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterMigration(&gormigrate.Migration{
+		ID: "202610162100_add_metrics_token_to_projects",
+		Migrate: func(tx *gorm.DB) error {
+			if err := tx.Exec("ALTER TABLE projects ADD COLUMN metrics_token VARCHAR(64)").Error; err != nil {
+				return err
+			}
+
+			// Probe scrapes resolve the project by token
+			if err := tx.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_projects_metrics_token ON projects(metrics_token)").Error; err != nil {
+				return err
+			}
+
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			if err := tx.Exec("DROP INDEX IF EXISTS idx_projects_metrics_token").Error; err != nil {
+				return err
+			}
+
+			if err := tx.Exec("ALTER TABLE projects DROP COLUMN metrics_token").Error; err != nil {
+				return err
+			}
+
+			return nil
+		},
+	})
+}
@@ -0,0 +1,76 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterMigration(&gormigrate.Migration{
+		ID: "202610162200_add_check_run_rollups",
+		Migrate: func(tx *gorm.DB) error {
+			// Retention settings of each project's run history
+			if err := tx.Exec("ALTER TABLE projects ADD COLUMN run_retention_days INTEGER NOT NULL DEFAULT 30").Error; err != nil {
+				return err
+			}
+			if err := tx.Exec("ALTER TABLE projects ADD COLUMN rollup_retention_days INTEGER NOT NULL DEFAULT 365").Error; err != nil {
+				return err
+			}
+
+			// Create check_run_rollups table
+			if err := tx.Exec(`
+				CREATE TABLE check_run_rollups (
+					id UUID PRIMARY KEY DEFAULT uuidv7(),
+					check_id UUID NOT NULL,
+					region_id UUID NOT NULL,
+					resolution VARCHAR(10) NOT NULL,
+					bucket_start TIMESTAMPTZ NOT NULL,
+					total_runs INTEGER NOT NULL DEFAULT 0,
+					passing INTEGER NOT NULL DEFAULT 0,
+					degraded INTEGER NOT NULL DEFAULT 0,
+					failing INTEGER NOT NULL DEFAULT 0,
+					response_time_p50_us INTEGER,
+					response_time_p95_us INTEGER,
+					response_time_p99_us INTEGER,
+					network_timings JSONB,
+					created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+					updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+					FOREIGN KEY (check_id) REFERENCES checks(id) ON DELETE CASCADE,
+					FOREIGN KEY (region_id) REFERENCES regions(id) ON DELETE CASCADE
+				)
+			`).Error; err != nil {
+				return err
+			}
+
+			// Rollups are upserted per bucket and read by check over a range
+			if err := tx.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_check_run_rollups_bucket ON check_run_rollups(check_id, resolution, bucket_start, region_id)`).Error; err != nil {
+				return err
+			}
+
+			// Retention and the uptime and timings queries range over a check's runs by time
+			if err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_check_runs_check_id_created_at ON check_runs(check_id, created_at)`).Error; err != nil {
+				return err
+			}
+
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			if err := tx.Exec(`DROP INDEX IF EXISTS idx_check_runs_check_id_created_at`).Error; err != nil {
+				return err
+			}
+
+			if err := tx.Exec(`DROP TABLE IF EXISTS check_run_rollups`).Error; err != nil {
+				return err
+			}
+
+			if err := tx.Exec("ALTER TABLE projects DROP COLUMN rollup_retention_days").Error; err != nil {
+				return err
+			}
+			if err := tx.Exec("ALTER TABLE projects DROP COLUMN run_retention_days").Error; err != nil {
+				return err
+			}
+
+			return nil
+		},
+	})
+}
@@ -0,0 +1,115 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterMigration(&gormigrate.Migration{
+		ID: "202610162300_add_check_stats",
+		Migrate: func(tx *gorm.DB) error {
+			// Create check_stats table
+			if err := tx.Exec(`
+				CREATE TABLE check_stats (
+					check_id UUID PRIMARY KEY,
+					recent_runs JSONB NOT NULL DEFAULT '[]',
+					hourly_buckets JSONB NOT NULL DEFAULT '[]',
+					updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+					FOREIGN KEY (check_id) REFERENCES checks(id) ON DELETE CASCADE
+				)
+			`).Error; err != nil {
+				return err
+			}
+
+			// Seed the stats of every check from its runs: the latest 24 runs, and the
+			// hourly buckets of the last 7 days with latency histograms for the last 24
+			// hours. The histogram bounds are those of models.LatencyBoundsMs.
+			if err := tx.Exec(`
+				INSERT INTO check_stats (check_id, recent_runs, hourly_buckets)
+				SELECT
+					c.id,
+					COALESCE((
+						SELECT JSONB_AGG(JSONB_BUILD_OBJECT(
+							'id', r.id,
+							'timestamp', r.created_at,
+							'total_time_ms', r.total_time_ms,
+							'status', r.status
+						) ORDER BY r.created_at ASC, r.id ASC)
+						FROM (
+							SELECT
+								id,
+								created_at,
+								status,
+								CASE
+									WHEN response_ended_at > request_started_at
+									THEN FLOOR(EXTRACT(EPOCH FROM (response_ended_at - request_started_at)) * 1000)::integer
+									ELSE 0
+								END AS total_time_ms
+							FROM check_runs
+							WHERE check_id = c.id AND deleted_at IS NULL
+							ORDER BY created_at DESC, id DESC
+							LIMIT 24
+						) r
+					), '[]'::jsonb),
+					COALESCE((
+						SELECT JSONB_AGG(JSONB_STRIP_NULLS(JSONB_BUILD_OBJECT(
+							't', EXTRACT(EPOCH FROM h.hour)::bigint,
+							'n', h.runs,
+							'u', h.up,
+							'c', h.latency_count,
+							's', h.latency_sum,
+							'h', h.histogram
+						)) ORDER BY h.hour ASC)
+						FROM (
+							SELECT
+								g.hour,
+								SUM(g.runs) AS runs,
+								SUM(g.up) AS up,
+								SUM(g.latency_count) AS latency_count,
+								COALESCE(SUM(g.latency_sum), 0) AS latency_sum,
+								JSONB_OBJECT_AGG(g.bucket::text, g.latency_count)
+									FILTER (WHERE g.bucket IS NOT NULL AND g.hour + INTERVAL '1 hour' > NOW() - INTERVAL '24 hours') AS histogram
+							FROM (
+								SELECT
+									DATE_TRUNC('hour', created_at, 'UTC') AS hour,
+									WIDTH_BUCKET(latency_ms, ARRAY[10, 25, 50, 75, 100, 150, 200, 300, 400, 500, 750, 1000, 1500, 2000, 3000, 5000, 7500, 10000, 15000, 30000, 60000]::float8[]) AS bucket,
+									COUNT(*) FILTER (WHERE NOT in_maintenance) AS runs,
+									COUNT(*) FILTER (WHERE NOT in_maintenance AND status IN ('passing', 'degraded')) AS up,
+									COUNT(latency_ms) AS latency_count,
+									SUM(latency_ms) AS latency_sum
+								FROM (
+									SELECT
+										created_at,
+										status,
+										in_maintenance,
+										CASE
+											WHEN response_ended_at > request_started_at
+											THEN EXTRACT(EPOCH FROM (response_ended_at - request_started_at))::float8 * 1000
+										END AS latency_ms
+									FROM check_runs
+									WHERE check_id = c.id
+										AND deleted_at IS NULL
+										AND created_at + INTERVAL '1 hour' > NOW() - INTERVAL '7 days'
+								) runs
+								GROUP BY hour, bucket
+							) g
+							GROUP BY g.hour
+						) h
+					), '[]'::jsonb)
+				FROM checks c
+				WHERE c.deleted_at IS NULL
+			`).Error; err != nil {
+				return err
+			}
+
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			if err := tx.Exec(`DROP TABLE IF EXISTS check_stats`).Error; err != nil {
+				return err
+			}
+			return nil
+		},
+	})
+}
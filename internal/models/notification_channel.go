@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+type NotificationChannel struct {
+	ID        uuid.UUID               `gorm:"type:uuid;primaryKey;default:uuidv7()" json:"id"`
+	Name      string                  `gorm:"not null" json:"name"`
+	Type      NotificationChannelType `gorm:"type:varchar(20);not null" json:"type"`
+	Config    datatypes.JSON          `gorm:"type:jsonb" json:"config"`
+	IsEnabled bool                    `gorm:"default:true" json:"is_enabled"`
+
+	CreatedAt time.Time      `gorm:"type:timestamptz" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"type:timestamptz" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"type:timestamptz;index" json:"-"`
+
+	ProjectID uuid.UUID `gorm:"type:uuid;index;not null" json:"project_id"`
+	Project   Project   `gorm:"foreignKey:ProjectID" json:"project,omitempty"`
+}
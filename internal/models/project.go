@@ -11,6 +11,15 @@ type Project struct {
 	ID   uuid.UUID `gorm:"type:uuid;primaryKey;default:uuidv7()" json:"id"`
 	Name string    `gorm:"not null" json:"name"`
 
+	// MetricsToken authenticates Prometheus scrapes of the project's probe metrics.
+	// It is only shown when generated.
+	MetricsToken *string `gorm:"type:varchar(64);uniqueIndex" json:"-"`
+
+	// RunRetentionDays is how long runs are kept before being rolled up into hourly
+	// and daily rollups. Hourly rollups are kept for RollupRetentionDays.
+	RunRetentionDays    int `gorm:"type:integer;not null;default:30" json:"run_retention_days"`
+	RollupRetentionDays int `gorm:"type:integer;not null;default:365" json:"rollup_retention_days"`
+
 	CreatedAt time.Time      `gorm:"type:timestamptz" json:"created_at"`
 	UpdatedAt time.Time      `gorm:"type:timestamptz" json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"type:timestamptz;index" json:"-"`
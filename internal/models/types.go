@@ -8,6 +8,7 @@ const (
 	CheckTypeDNS       CheckType = "dns"
 	CheckTypeBrowser   CheckType = "browser"
 	CheckTypeHeartbeat CheckType = "heartbeat"
+	CheckTypeMultiStep CheckType = "multistep"
 )
 
 type CheckRunStatus string
@@ -70,3 +71,30 @@ const (
 	DNSResolverProtocolUDP DNSResolverProtocolType = "udp"
 	DNSResolverProtocolTCP DNSResolverProtocolType = "tcp"
 )
+
+type NotificationChannelType string
+
+const (
+	NotificationChannelTypeEmail   NotificationChannelType = "email"
+	NotificationChannelTypeWebhook NotificationChannelType = "webhook"
+	NotificationChannelTypeSlack   NotificationChannelType = "slack"
+)
+
+type IncidentStatus string
+
+const (
+	IncidentStatusOpen         IncidentStatus = "open"
+	IncidentStatusAcknowledged IncidentStatus = "acknowledged"
+	IncidentStatusResolved     IncidentStatus = "resolved"
+)
+
+type IncidentEventType string
+
+const (
+	IncidentEventOpened       IncidentEventType = "opened"
+	IncidentEventAlert        IncidentEventType = "alert"
+	IncidentEventAcknowledged IncidentEventType = "acknowledged"
+	IncidentEventAssigned     IncidentEventType = "assigned"
+	IncidentEventNote         IncidentEventType = "note"
+	IncidentEventResolved     IncidentEventType = "resolved"
+)
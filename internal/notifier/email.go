@@ -0,0 +1,87 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+
+	"gorm.io/datatypes"
+
+	"pulse/internal/email"
+	"pulse/internal/models"
+)
+
+// EmailConfig is the config of an email notification channel.
+type EmailConfig struct {
+	Addresses []string `json:"addresses"`
+}
+
+// emailSender delivers notifications through the email service.
+type emailSender struct {
+	service *email.Service
+}
+
+func newEmailSender(service *email.Service) *emailSender {
+	return &emailSender{service: service}
+}
+
+// ValidateConfig checks that at least one valid address is configured.
+func (s *emailSender) ValidateConfig(config datatypes.JSON) error {
+	_, err := parseEmailConfig(config)
+	return err
+}
+
+// Send emails the notification to every configured address.
+func (s *emailSender) Send(ctx context.Context, channel *models.NotificationChannel, n *Notification) error {
+	if s.service == nil {
+		return errors.New("email service is not configured")
+	}
+
+	cfg, err := parseEmailConfig(channel.Config)
+	if err != nil {
+		return err
+	}
+
+	details := &email.AlertDetails{
+		CheckName:      n.CheckName,
+		ProjectName:    n.ProjectName,
+		Status:         string(n.Status),
+		PreviousStatus: string(n.PreviousStatus),
+		RegionName:     n.RegionName,
+		CheckURL:       n.URL,
+		OccurredAt:     n.OccurredAt,
+	}
+	if n.FailureReason != nil {
+		details.FailureReason = string(*n.FailureReason)
+	}
+
+	var errs []error
+	for _, address := range cfg.Addresses {
+		if err := s.service.SendAlertEmail(ctx, address, details); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", address, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func parseEmailConfig(config datatypes.JSON) (*EmailConfig, error) {
+	var cfg EmailConfig
+	if err := json.Unmarshal(config, &cfg); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidChannelConfig, err)
+	}
+
+	if len(cfg.Addresses) == 0 {
+		return nil, fmt.Errorf("%w: at least one address is required", ErrInvalidChannelConfig)
+	}
+
+	for _, address := range cfg.Addresses {
+		if _, err := mail.ParseAddress(address); err != nil {
+			return nil, fmt.Errorf("%w: invalid address %q", ErrInvalidChannelConfig, address)
+		}
+	}
+
+	return &cfg, nil
+}
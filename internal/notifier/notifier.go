@@ -0,0 +1,148 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+
+	"pulse/internal/email"
+	"pulse/internal/metrics"
+	"pulse/internal/models"
+	"pulse/internal/store"
+)
+
+const (
+	// SendTimeout is the maximum time a single channel delivery may take
+	SendTimeout = 30 * time.Second
+)
+
+var (
+	// ErrUnsupportedChannelType is returned when no sender exists for a channel type.
+	ErrUnsupportedChannelType = errors.New("unsupported notification channel type")
+	// ErrInvalidChannelConfig is returned when a channel's config does not match its type.
+	ErrInvalidChannelConfig = errors.New("invalid notification channel config")
+)
+
+// Notification is the channel-agnostic description of an alert.
+type Notification struct {
+	AlertID        uuid.UUID             `json:"alert_id"`
+	RunID          uuid.UUID             `json:"run_id"`
+	CheckID        uuid.UUID             `json:"check_id"`
+	CheckName      string                `json:"check_name"`
+	ProjectID      uuid.UUID             `json:"project_id"`
+	ProjectName    string                `json:"project_name"`
+	RegionCode     string                `json:"region_code"`
+	RegionName     string                `json:"region_name"`
+	Status         models.CheckRunStatus `json:"status"`
+	PreviousStatus models.CheckRunStatus `json:"previous_status"`
+	FailureReason  *models.FailureReason `json:"failure_reason,omitempty"`
+	URL            string                `json:"url"`
+	OccurredAt     time.Time             `json:"occurred_at"`
+}
+
+// Title returns a short human-readable summary of the notification.
+func (n *Notification) Title() string {
+	return fmt.Sprintf("%s is %s", n.CheckName, n.Status)
+}
+
+// Sender delivers a notification through a single channel.
+type Sender interface {
+	Send(ctx context.Context, channel *models.NotificationChannel, n *Notification) error
+	ValidateConfig(config datatypes.JSON) error
+}
+
+// Dispatcher fans out notifications to every channel a check is subscribed to.
+type Dispatcher struct {
+	store       *store.Store
+	senders     map[models.NotificationChannelType]Sender
+	frontendURL string
+}
+
+// NewDispatcher creates a dispatcher with the built-in senders.
+// emailService may be nil, in which case email channels are skipped.
+func NewDispatcher(s *store.Store, emailService *email.Service, frontendURL string) *Dispatcher {
+	d := &Dispatcher{
+		store:       s,
+		senders:     make(map[models.NotificationChannelType]Sender),
+		frontendURL: frontendURL,
+	}
+
+	d.senders[models.NotificationChannelTypeEmail] = newEmailSender(emailService)
+	d.senders[models.NotificationChannelTypeWebhook] = newWebhookSender()
+	d.senders[models.NotificationChannelTypeSlack] = newSlackSender()
+
+	return d
+}
+
+// ValidateConfig checks that config is valid for the given channel type.
+func (d *Dispatcher) ValidateConfig(channelType models.NotificationChannelType, config datatypes.JSON) error {
+	sender, ok := d.senders[channelType]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnsupportedChannelType, channelType)
+	}
+	return sender.ValidateConfig(config)
+}
+
+// CheckURL builds the frontend link for a check.
+func (d *Dispatcher) CheckURL(projectID, checkID uuid.UUID) string {
+	return fmt.Sprintf("%s/projects/%s/checks/%s", d.frontendURL, projectID, checkID)
+}
+
+// Send delivers a notification to a single channel.
+func (d *Dispatcher) Send(ctx context.Context, channel *models.NotificationChannel, n *Notification) error {
+	sender, ok := d.senders[channel.Type]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnsupportedChannelType, channel.Type)
+	}
+
+	if err := sender.Send(ctx, channel, n); err != nil {
+		return err
+	}
+
+	metrics.IncrementAlertsSent(string(channel.Type))
+	return nil
+}
+
+// Dispatch delivers a notification to all enabled channels of a check concurrently.
+// Delivery errors are logged per channel and do not stop the other deliveries.
+func (d *Dispatcher) Dispatch(ctx context.Context, n *Notification) {
+	channels, err := d.store.GetNotificationChannelsByCheck(n.CheckID)
+	if err != nil {
+		log.Printf("Error loading notification channels for check %s: %v", n.CheckID, err)
+		return
+	}
+
+	if len(channels) == 0 {
+		return
+	}
+
+	var wg sync.WaitGroup
+	for i := range channels {
+		wg.Add(1)
+		go func(channel *models.NotificationChannel) {
+			defer wg.Done()
+
+			sendCtx, cancel := context.WithTimeout(ctx, SendTimeout)
+			defer cancel()
+
+			if err := d.Send(sendCtx, channel, n); err != nil {
+				log.Printf("Failed to send notification for check %s to channel %s (%s): %v", n.CheckID, channel.Name, channel.Type, err)
+				return
+			}
+
+			log.Printf("Notification for check %s sent to channel %s (%s)", n.CheckID, channel.Name, channel.Type)
+		}(&channels[i])
+	}
+	wg.Wait()
+}
+
+// DispatchAsync delivers a notification in a background goroutine.
+func (d *Dispatcher) DispatchAsync(n *Notification) {
+	go d.Dispatch(context.Background(), n)
+}
@@ -0,0 +1,121 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"gorm.io/datatypes"
+
+	"pulse/internal/models"
+)
+
+// SlackConfig is the config of a Slack-compatible incoming webhook channel.
+// Mattermost, Rocket.Chat and Discord's /slack endpoint accept the same payload.
+type SlackConfig struct {
+	URL string `json:"url"`
+}
+
+// slackSender posts notifications to Slack incoming webhooks.
+type slackSender struct {
+	client *http.Client
+}
+
+func newSlackSender() *slackSender {
+	return &slackSender{client: &http.Client{Timeout: SendTimeout}}
+}
+
+// ValidateConfig checks the incoming webhook URL.
+func (s *slackSender) ValidateConfig(config datatypes.JSON) error {
+	_, err := parseSlackConfig(config)
+	return err
+}
+
+// Send posts the notification as a Slack message attachment.
+func (s *slackSender) Send(ctx context.Context, channel *models.NotificationChannel, n *Notification) error {
+	cfg, err := parseSlackConfig(channel.Config)
+	if err != nil {
+		return err
+	}
+
+	fields := []map[string]interface{}{
+		{"title": "Project", "value": n.ProjectName, "short": true},
+		{"title": "Region", "value": n.RegionName, "short": true},
+		{"title": "Previous status", "value": string(n.PreviousStatus), "short": true},
+	}
+	if n.FailureReason != nil {
+		fields = append(fields, map[string]interface{}{"title": "Reason", "value": string(*n.FailureReason), "short": true})
+	}
+
+	payload := map[string]interface{}{
+		"text": fmt.Sprintf("%s %s", statusEmoji(n.Status), n.Title()),
+		"attachments": []map[string]interface{}{
+			{
+				"color":      statusColor(n.Status),
+				"title":      n.CheckName,
+				"title_link": n.URL,
+				"fields":     fields,
+				"ts":         n.OccurredAt.Unix(),
+				"footer":     "Pulse",
+				"fallback":   fmt.Sprintf("%s (%s) at %s", n.Title(), n.RegionName, n.OccurredAt.Format(time.RFC3339)),
+			},
+		},
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return doRequest(s.client, req)
+}
+
+func parseSlackConfig(config datatypes.JSON) (*SlackConfig, error) {
+	var cfg SlackConfig
+	if err := json.Unmarshal(config, &cfg); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidChannelConfig, err)
+	}
+
+	if err := validateURL(cfg.URL); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+// statusColor returns the attachment color for a status.
+func statusColor(status models.CheckRunStatus) string {
+	switch status {
+	case models.CheckRunStatusPassing:
+		return "#22c55e"
+	case models.CheckRunStatusDegraded:
+		return "#f59e0b"
+	case models.CheckRunStatusFailing:
+		return "#ef4444"
+	default:
+		return "#737373"
+	}
+}
+
+// statusEmoji returns the emoji shortcode prefixed to the message text.
+func statusEmoji(status models.CheckRunStatus) string {
+	switch status {
+	case models.CheckRunStatusPassing:
+		return ":white_check_mark:"
+	case models.CheckRunStatusDegraded:
+		return ":warning:"
+	case models.CheckRunStatusFailing:
+		return ":rotating_light:"
+	default:
+		return ":grey_question:"
+	}
+}
@@ -0,0 +1,136 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"gorm.io/datatypes"
+
+	"pulse/internal/models"
+)
+
+const (
+	// SignatureHeader carries the HMAC-SHA256 signature of webhook payloads
+	SignatureHeader = "X-Pulse-Signature"
+)
+
+// WebhookConfig is the config of a generic webhook notification channel.
+type WebhookConfig struct {
+	URL     string            `json:"url"`
+	Method  string            `json:"method,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Secret  string            `json:"secret,omitempty"`
+}
+
+// webhookPayload is the JSON body posted to generic webhooks.
+type webhookPayload struct {
+	Event string `json:"event"`
+	*Notification
+}
+
+// webhookSender posts the notification as JSON to an arbitrary URL.
+type webhookSender struct {
+	client *http.Client
+}
+
+func newWebhookSender() *webhookSender {
+	return &webhookSender{client: &http.Client{Timeout: SendTimeout}}
+}
+
+// ValidateConfig checks the URL and method of the webhook.
+func (s *webhookSender) ValidateConfig(config datatypes.JSON) error {
+	_, err := parseWebhookConfig(config)
+	return err
+}
+
+// Send posts the notification to the webhook, signing it when a secret is set.
+func (s *webhookSender) Send(ctx context.Context, channel *models.NotificationChannel, n *Notification) error {
+	cfg, err := parseWebhookConfig(channel.Config)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(webhookPayload{Event: "check.status_changed", Notification: n})
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, cfg.Method, cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Pulse-Webhook/1.0")
+	for k, v := range cfg.Headers {
+		req.Header.Set(k, v)
+	}
+
+	if cfg.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(cfg.Secret))
+		mac.Write(body)
+		req.Header.Set(SignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	return doRequest(s.client, req)
+}
+
+func parseWebhookConfig(config datatypes.JSON) (*WebhookConfig, error) {
+	var cfg WebhookConfig
+	if err := json.Unmarshal(config, &cfg); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidChannelConfig, err)
+	}
+
+	if err := validateURL(cfg.URL); err != nil {
+		return nil, err
+	}
+
+	if cfg.Method == "" {
+		cfg.Method = http.MethodPost
+	}
+	cfg.Method = strings.ToUpper(cfg.Method)
+	if cfg.Method != http.MethodPost && cfg.Method != http.MethodPut && cfg.Method != http.MethodPatch {
+		return nil, fmt.Errorf("%w: method must be POST, PUT or PATCH", ErrInvalidChannelConfig)
+	}
+
+	return &cfg, nil
+}
+
+// validateURL checks that rawURL is an absolute http(s) URL.
+func validateURL(rawURL string) error {
+	if rawURL == "" {
+		return fmt.Errorf("%w: url is required", ErrInvalidChannelConfig)
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("%w: url must be an absolute http(s) URL", ErrInvalidChannelConfig)
+	}
+
+	return nil
+}
+
+// doRequest executes req and treats any non-2xx response as an error.
+func doRequest(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(snippet)))
+	}
+
+	return nil
+}
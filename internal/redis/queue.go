@@ -0,0 +1,405 @@
+package redis
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"pulse/internal/models"
+)
+
+const (
+	// JobVisibilityTimeout is how long a dequeued job may go without being
+	// acknowledged or having its lease extended before the reaper assumes its
+	// worker died and requeues it
+	JobVisibilityTimeout = 10 * time.Minute
+	// MaxJobAttempts is how often a job is delivered before it is dead-lettered
+	MaxJobAttempts = 3
+	// maxDeadJobs bounds the dead-letter list of a region
+	maxDeadJobs = 1000
+)
+
+var (
+	// ErrNoJob is returned when no job arrived before the dequeue timeout.
+	ErrNoJob = errors.New("no job available")
+	// ErrJobNotFound is returned when a dead job to replay doesn't exist.
+	ErrJobNotFound = errors.New("job not found")
+	// ErrJobTimeout is returned when a job's result didn't arrive in time.
+	ErrJobTimeout = errors.New("timed out waiting for job result")
+)
+
+// Job is a check run queued for the workers of one region.
+type Job struct {
+	ID         uuid.UUID `json:"id"`
+	CheckID    uuid.UUID `json:"check_id"`
+	ProjectID  uuid.UUID `json:"project_id"`
+	RegionCode string    `json:"region"`
+	Attempts   int       `json:"attempts"` // deliveries that were not acknowledged
+	EnqueuedAt time.Time `json:"enqueued_at"`
+
+	// Manually triggered runs skip ahead of scheduled ones and are stored under
+	// an ID handed out when they were triggered
+	Priority bool      `json:"priority,omitempty"`
+	RunID    uuid.UUID `json:"run_id"`
+
+	// Test jobs run an unsaved check and hand the run back with PushJobResult
+	// instead of storing it
+	Check *models.Check `json:"check,omitempty"`
+
+	raw      string // payload as stored in the processing list
+	consumer string // worker holding the job
+}
+
+// DeadJob is a job that exhausted its attempts.
+type DeadJob struct {
+	Job
+	Reason string    `json:"reason"`
+	DiedAt time.Time `json:"died_at"`
+
+	raw string // payload as stored in the dead-letter list
+}
+
+// Keys of a region's queue: pending jobs, one processing list per worker, the
+// visibility deadlines of processing jobs, the known workers and dead jobs.
+func jobQueueKey(regionCode string) string {
+	return fmt.Sprintf("pulse:jobs:%s", regionCode)
+}
+
+func processingKey(regionCode, consumer string) string {
+	return fmt.Sprintf("pulse:jobs:%s:processing:%s", regionCode, consumer)
+}
+
+func leasesKey(regionCode string) string {
+	return fmt.Sprintf("pulse:jobs:%s:leases", regionCode)
+}
+
+func consumersKey(regionCode string) string {
+	return fmt.Sprintf("pulse:jobs:%s:consumers", regionCode)
+}
+
+func deadJobsKey(regionCode string) string {
+	return fmt.Sprintf("pulse:jobs:%s:dead", regionCode)
+}
+
+// leaseMember identifies a processing job in the leases set
+func leaseMember(consumer, raw string) string {
+	return consumer + "|" + raw
+}
+
+// requeueScript removes a job from a processing list and pushes its next payload to
+// the queue (retried jobs go to the front) or the dead-letter list. It does nothing
+// but drop the lease if the job was acknowledged in the meantime.
+var requeueScript = redis.NewScript(`
+if redis.call('LREM', KEYS[1], 1, ARGV[1]) == 0 then
+	redis.call('ZREM', KEYS[2], ARGV[2])
+	return 0
+end
+redis.call('ZREM', KEYS[2], ARGV[2])
+if ARGV[4] == 'dead' then
+	redis.call('LPUSH', KEYS[3], ARGV[3])
+	redis.call('LTRIM', KEYS[3], 0, tonumber(ARGV[5]) - 1)
+else
+	redis.call('RPUSH', KEYS[3], ARGV[3])
+end
+return 1
+`)
+
+// replayScript moves a dead job back to the queue
+var replayScript = redis.NewScript(`
+if redis.call('LREM', KEYS[1], 1, ARGV[1]) == 0 then
+	return 0
+end
+redis.call('RPUSH', KEYS[2], ARGV[2])
+return 1
+`)
+
+// EnqueueJob pushes a job onto the queue of the job's region. Priority jobs are
+// pushed to the front of the queue.
+func (c *Client) EnqueueJob(job Job) error {
+	if job.RegionCode == "" {
+		return fmt.Errorf("job for check %s has no region", job.CheckID)
+	}
+	if job.ID == uuid.Nil {
+		job.ID = uuid.New()
+	}
+	if job.EnqueuedAt.IsZero() {
+		job.EnqueuedAt = time.Now().UTC()
+	}
+
+	jobData, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job: %w", err)
+	}
+
+	// Workers take jobs from the right
+	push := c.client.LPush
+	if job.Priority {
+		push = c.client.RPush
+	}
+	if err := push(c.ctx, jobQueueKey(job.RegionCode), jobData).Err(); err != nil {
+		return fmt.Errorf("failed to enqueue job: %w", err)
+	}
+
+	return nil
+}
+
+// DequeueJob blocks for up to timeout waiting for a job on the queue of a region.
+// The job moves to the consumer's processing list until it is acknowledged with
+// AckJob; if that doesn't happen within JobVisibilityTimeout it is delivered again,
+// unless the lease is extended with ExtendJobLease.
+func (c *Client) DequeueJob(regionCode, consumer string, timeout time.Duration) (*Job, error) {
+	// Register the consumer first, so the reaper finds its processing list even if
+	// the worker dies right after the move
+	if err := c.client.SAdd(c.ctx, consumersKey(regionCode), consumer).Err(); err != nil {
+		return nil, fmt.Errorf("failed to register consumer: %w", err)
+	}
+
+	raw, err := c.client.BLMove(c.ctx, jobQueueKey(regionCode), processingKey(regionCode, consumer), "RIGHT", "LEFT", timeout).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, ErrNoJob
+		}
+		return nil, fmt.Errorf("failed to dequeue job: %w", err)
+	}
+
+	deadline := time.Now().Add(JobVisibilityTimeout)
+	if err := c.client.ZAdd(c.ctx, leasesKey(regionCode), redis.Z{
+		Score:  float64(deadline.Unix()),
+		Member: leaseMember(consumer, raw),
+	}).Err(); err != nil {
+		return nil, fmt.Errorf("failed to lease job: %w", err)
+	}
+
+	var job Job
+	if err := json.Unmarshal([]byte(raw), &job); err != nil {
+		// A malformed payload can never be processed, drop it
+		_ = c.client.LRem(c.ctx, processingKey(regionCode, consumer), 1, raw).Err()
+		_ = c.client.ZRem(c.ctx, leasesKey(regionCode), leaseMember(consumer, raw)).Err()
+		return nil, fmt.Errorf("failed to unmarshal job: %w", err)
+	}
+	job.raw = raw
+	job.consumer = consumer
+	if job.RegionCode == "" {
+		job.RegionCode = regionCode
+	}
+
+	return &job, nil
+}
+
+// ExtendJobLease pushes the visibility deadline of a dequeued job to
+// JobVisibilityTimeout from now, so a long run isn't delivered again while its
+// worker is still on it. Jobs acknowledged or requeued in the meantime are left
+// alone.
+func (c *Client) ExtendJobLease(job *Job) error {
+	deadline := time.Now().Add(JobVisibilityTimeout)
+	if err := c.client.ZAddXX(c.ctx, leasesKey(job.RegionCode), redis.Z{
+		Score:  float64(deadline.Unix()),
+		Member: leaseMember(job.consumer, job.raw),
+	}).Err(); err != nil {
+		return fmt.Errorf("failed to extend job lease: %w", err)
+	}
+	return nil
+}
+
+// AckJob marks a dequeued job as done, removing it from the processing list.
+func (c *Client) AckJob(job *Job) error {
+	_, err := c.client.TxPipelined(c.ctx, func(pipe redis.Pipeliner) error {
+		pipe.LRem(c.ctx, processingKey(job.RegionCode, job.consumer), 1, job.raw)
+		pipe.ZRem(c.ctx, leasesKey(job.RegionCode), leaseMember(job.consumer, job.raw))
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to acknowledge job: %w", err)
+	}
+	return nil
+}
+
+// RetryJob gives up on a dequeued job, requeueing it or dead-lettering it once it
+// has used up its attempts.
+func (c *Client) RetryJob(job *Job, reason string) error {
+	_, err := c.requeue(job.RegionCode, job.consumer, job.raw, reason, true)
+	return err
+}
+
+// ReleaseJob hands a dequeued job back to the front of the queue without counting
+// the delivery as an attempt, e.g. when its worker shuts down.
+func (c *Client) ReleaseJob(job *Job) error {
+	_, err := c.requeue(job.RegionCode, job.consumer, job.raw, "", false)
+	return err
+}
+
+// UnregisterConsumer forgets a consumer whose worker stopped. Consumers with jobs
+// still processing are kept, so the reaper finds them.
+func (c *Client) UnregisterConsumer(regionCode, consumer string) error {
+	processing, err := c.client.LLen(c.ctx, processingKey(regionCode, consumer)).Result()
+	if err != nil {
+		return fmt.Errorf("failed to count processing jobs: %w", err)
+	}
+	if processing > 0 {
+		return nil
+	}
+
+	if err := c.client.SRem(c.ctx, consumersKey(regionCode), consumer).Err(); err != nil {
+		return fmt.Errorf("failed to unregister consumer: %w", err)
+	}
+	return nil
+}
+
+// RequeueExpiredJobs requeues the jobs of a region whose visibility timeout expired,
+// and returns how many it requeued or dead-lettered.
+func (c *Client) RequeueExpiredJobs(regionCode string) (int, error) {
+	// Jobs moved by a worker that died before leasing them get a lease now
+	consumers, err := c.client.SMembers(c.ctx, consumersKey(regionCode)).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list consumers: %w", err)
+	}
+	deadline := float64(time.Now().Add(JobVisibilityTimeout).Unix())
+	for _, consumer := range consumers {
+		raws, err := c.client.LRange(c.ctx, processingKey(regionCode, consumer), 0, -1).Result()
+		if err != nil {
+			return 0, fmt.Errorf("failed to list processing jobs: %w", err)
+		}
+		if len(raws) == 0 {
+			continue
+		}
+		members := make([]redis.Z, 0, len(raws))
+		for _, raw := range raws {
+			members = append(members, redis.Z{Score: deadline, Member: leaseMember(consumer, raw)})
+		}
+		if err := c.client.ZAddNX(c.ctx, leasesKey(regionCode), members...).Err(); err != nil {
+			return 0, fmt.Errorf("failed to lease processing jobs: %w", err)
+		}
+	}
+
+	expired, err := c.client.ZRangeByScore(c.ctx, leasesKey(regionCode), &redis.ZRangeBy{
+		Min: "-inf",
+		Max: fmt.Sprintf("%d", time.Now().Unix()),
+	}).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list expired jobs: %w", err)
+	}
+
+	requeued := 0
+	for _, member := range expired {
+		consumer, raw, ok := strings.Cut(member, "|")
+		if !ok {
+			_ = c.client.ZRem(c.ctx, leasesKey(regionCode), member).Err()
+			continue
+		}
+		moved, err := c.requeue(regionCode, consumer, raw, "visibility timeout expired", true)
+		if err != nil {
+			return requeued, err
+		}
+		if moved {
+			requeued++
+		}
+	}
+
+	return requeued, nil
+}
+
+// requeue moves a processing job back to the queue, with one more attempt if
+// countAttempt is set, or to the dead-letter list. It returns false if the job was
+// no longer processing.
+func (c *Client) requeue(regionCode, consumer, raw, reason string, countAttempt bool) (bool, error) {
+	var job Job
+	if err := json.Unmarshal([]byte(raw), &job); err != nil {
+		return false, fmt.Errorf("failed to unmarshal job: %w", err)
+	}
+	if job.ID == uuid.Nil {
+		job.ID = uuid.New()
+	}
+	if countAttempt {
+		job.Attempts++
+	}
+
+	destination, mode := jobQueueKey(regionCode), "retry"
+	var payload []byte
+	var err error
+	if job.Attempts >= MaxJobAttempts {
+		destination, mode = deadJobsKey(regionCode), "dead"
+		payload, err = json.Marshal(DeadJob{Job: job, Reason: reason, DiedAt: time.Now().UTC()})
+	} else {
+		payload, err = json.Marshal(job)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal job: %w", err)
+	}
+
+	moved, err := requeueScript.Run(c.ctx, c.client,
+		[]string{processingKey(regionCode, consumer), leasesKey(regionCode), destination},
+		raw, leaseMember(consumer, raw), string(payload), mode, maxDeadJobs,
+	).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to requeue job: %w", err)
+	}
+
+	return moved == 1, nil
+}
+
+// ListDeadJobs returns the dead-lettered jobs of a region, most recent first.
+func (c *Client) ListDeadJobs(regionCode string) ([]DeadJob, error) {
+	raws, err := c.client.LRange(c.ctx, deadJobsKey(regionCode), 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead jobs: %w", err)
+	}
+
+	jobs := make([]DeadJob, 0, len(raws))
+	for _, raw := range raws {
+		var job DeadJob
+		if err := json.Unmarshal([]byte(raw), &job); err != nil {
+			continue
+		}
+		job.raw = raw
+		jobs = append(jobs, job)
+	}
+
+	return jobs, nil
+}
+
+// ReplayDeadJob moves a dead job back to the queue of its region with its attempts
+// reset. It returns ErrJobNotFound if the job isn't in the dead-letter list.
+func (c *Client) ReplayDeadJob(regionCode string, jobID uuid.UUID) (*Job, error) {
+	jobs, err := c.ListDeadJobs(regionCode)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, dead := range jobs {
+		if dead.ID != jobID {
+			continue
+		}
+
+		job := dead.Job
+		job.Attempts = 0
+		job.EnqueuedAt = time.Now().UTC()
+		payload, err := json.Marshal(job)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal job: %w", err)
+		}
+
+		moved, err := replayScript.Run(c.ctx, c.client,
+			[]string{deadJobsKey(regionCode), jobQueueKey(regionCode)},
+			dead.raw, string(payload),
+		).Int()
+		if err != nil {
+			return nil, fmt.Errorf("failed to replay job: %w", err)
+		}
+		if moved == 0 {
+			return nil, ErrJobNotFound
+		}
+		return &job, nil
+	}
+
+	return nil, ErrJobNotFound
+}
+
+// GetQueueDepth returns the number of jobs in the queue of a region
+func (c *Client) GetQueueDepth(regionCode string) (int64, error) {
+	return c.client.LLen(c.ctx, jobQueueKey(regionCode)).Result()
+}
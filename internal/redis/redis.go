@@ -51,50 +51,6 @@ func (c *Client) HealthCheck() error {
 	return c.client.Ping(c.ctx).Err()
 }
 
-func (c *Client) EnqueueJob(checkID uuid.UUID) error {
-	jobData, err := json.Marshal(checkID.String())
-	if err != nil {
-		return fmt.Errorf("failed to marshal job: %w", err)
-	}
-
-	if err := c.client.LPush(c.ctx, "pulse:jobs", jobData).Err(); err != nil {
-		return fmt.Errorf("failed to enqueue job: %w", err)
-	}
-
-	return nil
-}
-
-func (c *Client) DequeueJob(timeout time.Duration) (uuid.UUID, error) {
-	result, err := c.client.BRPop(c.ctx, timeout, "pulse:jobs").Result()
-	if err != nil {
-		if err == redis.Nil {
-			return uuid.Nil, fmt.Errorf("no job available")
-		}
-		return uuid.Nil, fmt.Errorf("failed to dequeue job: %w", err)
-	}
-
-	if len(result) < 2 {
-		return uuid.Nil, fmt.Errorf("invalid job format")
-	}
-
-	var checkIDStr string
-	if err := json.Unmarshal([]byte(result[1]), &checkIDStr); err != nil {
-		return uuid.Nil, fmt.Errorf("failed to unmarshal job: %w", err)
-	}
-
-	checkID, err := uuid.Parse(checkIDStr)
-	if err != nil {
-		return uuid.Nil, fmt.Errorf("failed to parse check ID: %w", err)
-	}
-
-	return checkID, nil
-}
-
-// GetQueueDepth returns the number of jobs in the queue
-func (c *Client) GetQueueDepth() (int64, error) {
-	return c.client.LLen(c.ctx, "pulse:jobs").Result()
-}
-
 func (c *Client) GetCheck(checkID uuid.UUID) (*models.Check, error) {
 	key := fmt.Sprintf("pulse:check:%s", checkID.String())
 	val, err := c.client.Get(c.ctx, key).Result()
@@ -152,3 +108,11 @@ func (c *Client) DeleteSession(jti string) error {
 	key := fmt.Sprintf("pulse:session:%s", jti)
 	return c.client.Del(c.ctx, key).Err()
 }
+
+// TryLock takes the named lock for ttl and reports whether it was free. Locks
+// aren't released but expire, so they also keep a periodic job from running more
+// than once per ttl across processes.
+func (c *Client) TryLock(name string, ttl time.Duration) (bool, error) {
+	key := fmt.Sprintf("pulse:lock:%s", name)
+	return c.client.SetNX(c.ctx, key, "locked", ttl).Result()
+}
@@ -0,0 +1,109 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// RunSubscription delivers the IDs of runs of a check as workers store them.
+type RunSubscription struct {
+	C <-chan uuid.UUID
+
+	pubsub *redis.PubSub
+}
+
+// Close stops the subscription and closes C.
+func (s *RunSubscription) Close() error {
+	return s.pubsub.Close()
+}
+
+func checkRunsChannel(checkID uuid.UUID) string {
+	return fmt.Sprintf("pulse:checks:%s:runs", checkID)
+}
+
+// PublishCheckRun announces that a run of a check was stored.
+func (c *Client) PublishCheckRun(checkID, runID uuid.UUID) error {
+	if err := c.client.Publish(c.ctx, checkRunsChannel(checkID), runID.String()).Err(); err != nil {
+		return fmt.Errorf("failed to publish check run: %w", err)
+	}
+	return nil
+}
+
+// SubscribeCheckRuns follows the runs of a check stored from now on, until ctx is
+// done or the subscription is closed.
+func (c *Client) SubscribeCheckRuns(ctx context.Context, checkID uuid.UUID) (*RunSubscription, error) {
+	pubsub := c.client.Subscribe(ctx, checkRunsChannel(checkID))
+
+	// Wait for the subscription to be confirmed, so no run published after this
+	// returns is missed
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, fmt.Errorf("failed to subscribe to check runs: %w", err)
+	}
+
+	runIDs := make(chan uuid.UUID)
+	go func() {
+		defer close(runIDs)
+		for msg := range pubsub.Channel() {
+			runID, err := uuid.Parse(msg.Payload)
+			if err != nil {
+				continue
+			}
+			select {
+			case runIDs <- runID:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return &RunSubscription{C: runIDs, pubsub: pubsub}, nil
+}
+
+// jobResultTTL is how long a job result waits for its caller
+const jobResultTTL = 5 * time.Minute
+
+func jobResultKey(jobID uuid.UUID) string {
+	return fmt.Sprintf("pulse:jobs:results:%s", jobID)
+}
+
+// PushJobResult hands the result of a job to the caller waiting in WaitJobResult.
+func (c *Client) PushJobResult(jobID uuid.UUID, result interface{}) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job result: %w", err)
+	}
+
+	key := jobResultKey(jobID)
+	if _, err := c.client.TxPipelined(c.ctx, func(pipe redis.Pipeliner) error {
+		pipe.LPush(c.ctx, key, data)
+		pipe.Expire(c.ctx, key, jobResultTTL)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to push job result: %w", err)
+	}
+	return nil
+}
+
+// WaitJobResult blocks for up to timeout waiting for the result of a job and
+// unmarshals it into result. It returns ErrJobTimeout if none arrived.
+func (c *Client) WaitJobResult(ctx context.Context, jobID uuid.UUID, timeout time.Duration, result interface{}) error {
+	values, err := c.client.BLPop(ctx, timeout, jobResultKey(jobID)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return ErrJobTimeout
+		}
+		return fmt.Errorf("failed to wait for job result: %w", err)
+	}
+
+	// BLPOP returns the key followed by the value
+	if err := json.Unmarshal([]byte(values[1]), result); err != nil {
+		return fmt.Errorf("failed to unmarshal job result: %w", err)
+	}
+	return nil
+}
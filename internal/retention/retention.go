@@ -0,0 +1,146 @@
+package retention
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"pulse/internal/models"
+	"pulse/internal/redis"
+	"pulse/internal/store"
+)
+
+const (
+	// interval is how often the retention of each project is applied
+	interval = time.Hour
+	// lockTTL keeps the other workers from applying the retention in the same
+	// interval. It is a little shorter than the interval so the next run isn't
+	// skipped when the workers' tickers drift.
+	lockTTL = interval - time.Minute
+	// lockName is the name of the lock taken by the worker applying the retention
+	lockName = "retention"
+)
+
+// Runner rolls up and deletes run history past each project's retention. Every
+// worker runs one; a lock in Redis makes a single worker apply the retention per
+// interval.
+type Runner struct {
+	store  *store.Store
+	redis  *redis.Client
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New creates a retention runner
+func New(s *store.Store, r *redis.Client) *Runner {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Runner{
+		store:  s,
+		redis:  r,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Start applies the retention now and then every interval
+func (r *Runner) Start() {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			r.runOnce()
+
+			select {
+			case <-r.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops the runner. A project being processed is finished up to the day
+// being rolled up.
+func (r *Runner) Stop() {
+	r.cancel()
+	r.wg.Wait()
+}
+
+// runOnce applies the retention of every project, unless another worker did in
+// this interval
+func (r *Runner) runOnce() {
+	locked, err := r.redis.TryLock(lockName, lockTTL)
+	if err != nil {
+		log.Printf("Retention: Error taking lock: %v", err)
+		return
+	}
+	if !locked {
+		return
+	}
+
+	projects, err := r.store.ListProjects()
+	if err != nil {
+		log.Printf("Retention: Error listing projects: %v", err)
+		return
+	}
+
+	now := time.Now().UTC()
+	for i := range projects {
+		if r.ctx.Err() != nil {
+			return
+		}
+		if err := r.apply(&projects[i], now); err != nil {
+			log.Printf("Retention: Error applying retention of project %s: %v", projects[i].ID, err)
+		}
+	}
+}
+
+// apply rolls up the runs of a project past its run retention, a day at a time so
+// a large backlog isn't rolled up in one transaction, and deletes the hourly
+// rollups past its rollup retention.
+func (r *Runner) apply(project *models.Project, now time.Time) error {
+	runCutoff := startOfDay(now.AddDate(0, 0, -project.RunRetentionDays))
+	// Hourly rollups are made as runs expire, so they can't expire before them
+	rollupCutoff := startOfDay(now.AddDate(0, 0, -max(project.RollupRetentionDays, project.RunRetentionDays)))
+
+	oldest, err := r.store.GetOldestRollableCheckRun(project.ID, runCutoff)
+	if err != nil {
+		return err
+	}
+
+	var rolledUp int64
+	if oldest != nil {
+		for day := startOfDay(*oldest); day.Before(runCutoff); day = day.AddDate(0, 0, 1) {
+			if r.ctx.Err() != nil {
+				break
+			}
+			n, err := r.store.RollupCheckRuns(project.ID, day, day.AddDate(0, 0, 1))
+			if err != nil {
+				return err
+			}
+			rolledUp += n
+		}
+	}
+
+	deleted, err := r.store.DeleteHourlyRollups(project.ID, rollupCutoff)
+	if err != nil {
+		return err
+	}
+
+	if rolledUp > 0 || deleted > 0 {
+		log.Printf("Retention: Rolled up %d runs and deleted %d hourly rollups of project %s", rolledUp, deleted, project.ID)
+	}
+	return nil
+}
+
+// startOfDay truncates t to the start of its day in UTC, the days of daily rollups
+func startOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
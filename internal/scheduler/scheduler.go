@@ -8,6 +8,10 @@ import (
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
+
+	"pulse/internal/heartbeat"
+	"pulse/internal/metrics"
 	"pulse/internal/models"
 	"pulse/internal/redis"
 	"pulse/internal/store"
@@ -18,6 +22,11 @@ const (
 	defaultShutdownTimeout = 30 * time.Second
 	defaultMaxRetries      = 3
 	defaultRetryDelay      = 5 * time.Second
+	// defaultClaimTimeout is how long a claimed check is held back from the other
+	// schedulers of its region, should this one die before enqueueing it
+	defaultClaimTimeout = 5 * time.Minute
+	// defaultMaxConcurrent bounds how many due checks are enqueued in parallel
+	defaultMaxConcurrent = 10
 )
 
 var (
@@ -33,6 +42,8 @@ type Config struct {
 	ShutdownTimeout time.Duration
 	MaxRetries      int
 	RetryDelay      time.Duration
+	ClaimTimeout    time.Duration
+	MaxConcurrent   int
 	Logger          *slog.Logger
 }
 
@@ -43,6 +54,8 @@ func DefaultConfig() *Config {
 		ShutdownTimeout: defaultShutdownTimeout,
 		MaxRetries:      defaultMaxRetries,
 		RetryDelay:      defaultRetryDelay,
+		ClaimTimeout:    defaultClaimTimeout,
+		MaxConcurrent:   defaultMaxConcurrent,
 		Logger:          slog.Default(),
 	}
 }
@@ -51,6 +64,8 @@ func DefaultConfig() *Config {
 type Scheduler struct {
 	store      *store.Store
 	redis      *redis.Client
+	heartbeats *heartbeat.Monitor
+	regionID   uuid.UUID
 	regionCode string
 	config     *Config
 	logger     *slog.Logger
@@ -67,8 +82,9 @@ type Scheduler struct {
 	lastPollSuccess bool
 }
 
-// New creates a new Scheduler with the given dependencies.
-func New(s *store.Store, r *redis.Client, regionCode string, config *Config) *Scheduler {
+// New creates a new Scheduler for a region with the given dependencies.
+// Heartbeat checks are not enqueued, hb records their missed pings instead.
+func New(s *store.Store, r *redis.Client, hb *heartbeat.Monitor, region *models.Region, config *Config) *Scheduler {
 	if config == nil {
 		config = DefaultConfig()
 	}
@@ -77,14 +93,24 @@ func New(s *store.Store, r *redis.Client, regionCode string, config *Config) *Sc
 		config.Logger = slog.Default()
 	}
 
+	if config.ClaimTimeout <= 0 {
+		config.ClaimTimeout = defaultClaimTimeout
+	}
+
+	if config.MaxConcurrent <= 0 {
+		config.MaxConcurrent = defaultMaxConcurrent
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &Scheduler{
 		store:      s,
 		redis:      r,
-		regionCode: regionCode,
+		heartbeats: hb,
+		regionID:   region.ID,
+		regionCode: region.Code,
 		config:     config,
-		logger:     config.Logger.With("component", "scheduler", "region", regionCode),
+		logger:     config.Logger.With("component", "scheduler", "region", region.Code),
 		ctx:        ctx,
 		cancel:     cancel,
 	}
@@ -172,10 +198,11 @@ func (s *Scheduler) pollOnce() {
 		"checks_processed", len(checks))
 }
 
-// getDueChecks retrieves checks that are due for execution with retry logic.
+// getDueChecks claims checks that are due for execution with retry logic.
+// Several schedulers may run for a region; each due check is claimed by only one.
 func (s *Scheduler) getDueChecks() ([]models.Check, error) {
 	for attempt := 0; attempt < s.config.MaxRetries; attempt++ {
-		checks, err := s.store.GetDueChecks(s.regionCode)
+		checks, err := s.store.ClaimDueChecks(s.regionID, s.config.ClaimTimeout)
 		if err == nil {
 			return checks, nil
 		}
@@ -201,8 +228,7 @@ func (s *Scheduler) getDueChecks() ([]models.Check, error) {
 // processChecks processes a batch of checks concurrently with bounded parallelism.
 func (s *Scheduler) processChecks(checks []models.Check) {
 	// Use a semaphore pattern to limit concurrency
-	const maxConcurrent = 10
-	sem := make(chan struct{}, maxConcurrent)
+	sem := make(chan struct{}, s.config.MaxConcurrent)
 
 	var wg sync.WaitGroup
 
@@ -238,9 +264,27 @@ func (s *Scheduler) processCheck(check *models.Check) {
 		"interval", check.Interval,
 	)
 
+	if check.Type == models.CheckTypeHeartbeat {
+		s.processHeartbeat(check, logger)
+		return
+	}
+
+	// Track how late the check was claimed
+	for _, state := range check.RegionStates {
+		if state.RegionID == s.regionID && state.NextRunAt != nil {
+			metrics.ObservePollLag(s.regionCode, time.Since(*state.NextRunAt))
+		}
+	}
+
 	// Enqueue the check
 	if err := s.enqueueCheck(check); err != nil {
 		logger.Error("failed to enqueue check", "error", err)
+		metrics.IncrementEnqueueFailures(s.regionCode)
+
+		// Release the claim so the check is picked up again on the next poll
+		if err := s.store.ScheduleCheckRegion(check.ID, s.regionID, time.Now().UTC()); err != nil {
+			logger.Error("failed to release check claim", "error", err)
+		}
 		return
 	}
 
@@ -253,12 +297,35 @@ func (s *Scheduler) processCheck(check *models.Check) {
 	logger.Info("check enqueued successfully", "next_run", check.NextRunAt)
 }
 
-// enqueueCheck adds a check to the Redis queue with retry logic.
+// processHeartbeat records a missed ping for an overdue heartbeat check. Only the
+// scheduler of the check's primary region handles it, so a miss is recorded once.
+func (s *Scheduler) processHeartbeat(check *models.Check, logger *slog.Logger) {
+	region := check.PrimaryRegion()
+	if region == nil || region.Code != s.regionCode {
+		// Other regions only look at the check again after an interval
+		if err := s.updateNextRun(check); err != nil {
+			logger.Error("failed to update next run time", "error", err)
+		}
+		return
+	}
+
+	run, err := s.heartbeats.CheckMissed(check)
+	if err != nil {
+		logger.Error("failed to check heartbeat", "error", err)
+		return
+	}
+
+	if run != nil {
+		logger.Warn("heartbeat ping missed", "deadline", check.HeartbeatDeadline())
+	}
+}
+
+// enqueueCheck adds a check to the Redis queue of this region with retry logic.
 func (s *Scheduler) enqueueCheck(check *models.Check) error {
 	var err error
 
 	for attempt := 0; attempt < s.config.MaxRetries; attempt++ {
-		err = s.redis.EnqueueJob(check.ID)
+		err = s.redis.EnqueueJob(redis.Job{CheckID: check.ID, ProjectID: check.ProjectID, RegionCode: s.regionCode})
 		if err == nil {
 			return nil
 		}
@@ -281,14 +348,22 @@ func (s *Scheduler) enqueueCheck(check *models.Check) error {
 	return fmt.Errorf("failed to enqueue after %d attempts: %w", s.config.MaxRetries, err)
 }
 
-// updateNextRun calculates and updates the next run time for a check.
+// updateNextRun calculates and updates the next run time of a check in this region.
+// It follows on from the run that was due rather than from now, so the cadence of
+// the check doesn't drift.
 func (s *Scheduler) updateNextRun(check *models.Check) error {
-	interval := check.IntervalDuration()
+	now := time.Now().UTC()
+	scheduled := now
+	for _, state := range check.RegionStates {
+		if state.RegionID == s.regionID && state.NextRunAt != nil {
+			scheduled = *state.NextRunAt
+		}
+	}
 
-	nextRun := time.Now().UTC().Add(interval)
+	nextRun := check.NextRunAfter(scheduled, now)
 
-	if err := s.store.UpdateCheckStatus(check.ID, nextRun, check.LastStatus); err != nil {
-		return fmt.Errorf("failed to update check status: %w", err)
+	if err := s.store.ScheduleCheckRegion(check.ID, s.regionID, nextRun); err != nil {
+		return fmt.Errorf("failed to update check schedule: %w", err)
 	}
 
 	// Update check object for logging
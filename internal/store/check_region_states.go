@@ -0,0 +1,152 @@
+package store
+
+import (
+	"time"
+
+	"pulse/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GetCheckRegionStates returns the scheduling state of each region of a check
+func (s *Store) GetCheckRegionStates(checkID uuid.UUID) ([]models.CheckRegionState, error) {
+	var states []models.CheckRegionState
+	if err := s.db.Preload("Region").
+		Joins("JOIN check_regions ON check_regions.check_id = check_region_states.check_id AND check_regions.region_id = check_region_states.region_id").
+		Where("check_region_states.check_id = ?", checkID).
+		Order("check_region_states.region_id").
+		Find(&states).Error; err != nil {
+		return nil, err
+	}
+	return states, nil
+}
+
+// ScheduleCheckRegion sets when a region next runs a check
+func (s *Store) ScheduleCheckRegion(checkID, regionID uuid.UUID, nextRun time.Time) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`
+			INSERT INTO check_region_states (check_id, region_id, next_run_at, updated_at)
+			VALUES (?, ?, ?, NOW())
+			ON CONFLICT (check_id, region_id) DO UPDATE
+			SET next_run_at = EXCLUDED.next_run_at, updated_at = NOW()
+		`, checkID, regionID, nextRun).Error; err != nil {
+			return err
+		}
+		return summarizeCheckRegionStates(tx, checkID)
+	})
+}
+
+// UpdateCheckRegionStatus records a run of a check in a region and when the region
+// next runs it. A nil nextRun keeps the region's schedule.
+func (s *Store) UpdateCheckRegionStatus(checkID, regionID uuid.UUID, nextRun *time.Time, lastStatus models.CheckRunStatus) error {
+	now := time.Now().UTC()
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`
+			INSERT INTO check_region_states (check_id, region_id, next_run_at, last_run_at, last_status, updated_at)
+			VALUES (?, ?, ?, ?, ?, NOW())
+			ON CONFLICT (check_id, region_id) DO UPDATE
+			SET next_run_at = COALESCE(EXCLUDED.next_run_at, check_region_states.next_run_at),
+				last_run_at = EXCLUDED.last_run_at,
+				last_status = EXCLUDED.last_status,
+				updated_at = NOW()
+		`, checkID, regionID, nextRun, now, string(lastStatus)).Error; err != nil {
+			return err
+		}
+
+		// The check's status is that of its latest run in any region
+		if err := tx.Model(&models.Check{}).Where("id = ?", checkID).Updates(map[string]interface{}{
+			"last_run_at": now,
+			"last_status": string(lastStatus),
+		}).Error; err != nil {
+			return err
+		}
+		return summarizeCheckRegionStates(tx, checkID)
+	})
+}
+
+// ClaimDueChecks returns the enabled checks of a region whose next run in that region
+// is due, and pushes their next run back by claimFor in the same statement. Rows are
+// locked with SKIP LOCKED, so concurrent schedulers of a region never claim the same
+// check; the claimer is expected to schedule the real next run once it enqueued the
+// check, otherwise the check becomes due again when the claim runs out.
+// The RegionStates of each check hold the region's state as it was before the claim,
+// so the next run can follow on from the run that was due.
+func (s *Store) ClaimDueChecks(regionID uuid.UUID, claimFor time.Duration) ([]models.Check, error) {
+	now := time.Now().UTC()
+	var claimed []models.CheckRegionState
+
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Regions added to a check have no state until their first run
+		if err := tx.Exec(`
+			INSERT INTO check_region_states (check_id, region_id, updated_at)
+			SELECT cr.check_id, cr.region_id, NOW()
+			FROM check_regions cr
+			WHERE cr.region_id = ?
+			AND NOT EXISTS (
+				SELECT 1 FROM check_region_states crs
+				WHERE crs.check_id = cr.check_id AND crs.region_id = cr.region_id
+			)
+			ON CONFLICT (check_id, region_id) DO NOTHING
+		`, regionID).Error; err != nil {
+			return err
+		}
+
+		return tx.Raw(`
+			WITH due AS (
+				SELECT crs.check_id, crs.region_id, crs.next_run_at, crs.last_run_at, crs.last_status
+				FROM check_region_states crs
+				JOIN check_regions cr ON cr.check_id = crs.check_id AND cr.region_id = crs.region_id
+				JOIN checks c ON c.id = crs.check_id
+				WHERE crs.region_id = ?
+				AND c.is_enabled = ?
+				AND c.deleted_at IS NULL
+				AND (crs.next_run_at IS NULL OR crs.next_run_at <= ?)
+				FOR UPDATE OF crs SKIP LOCKED
+			)
+			UPDATE check_region_states
+			SET next_run_at = ?, updated_at = NOW()
+			FROM due
+			WHERE check_region_states.check_id = due.check_id AND check_region_states.region_id = due.region_id
+			RETURNING due.check_id, due.region_id, due.next_run_at, due.last_run_at, due.last_status, check_region_states.updated_at
+		`, regionID, true, now, now.Add(claimFor)).Scan(&claimed).Error
+	}); err != nil {
+		return nil, err
+	}
+
+	if len(claimed) == 0 {
+		return nil, nil
+	}
+
+	checkIDs := make([]uuid.UUID, 0, len(claimed))
+	for _, state := range claimed {
+		checkIDs = append(checkIDs, state.CheckID)
+	}
+
+	var checks []models.Check
+	if err := s.db.Preload("Regions").Where("id IN ?", checkIDs).Find(&checks).Error; err != nil {
+		return nil, err
+	}
+
+	states := make(map[uuid.UUID]models.CheckRegionState, len(claimed))
+	for _, state := range claimed {
+		states[state.CheckID] = state
+	}
+	for i := range checks {
+		checks[i].RegionStates = []models.CheckRegionState{states[checks[i].ID]}
+	}
+	return checks, nil
+}
+
+// summarizeCheckRegionStates sets the check's next run to the earliest of its regions
+func summarizeCheckRegionStates(tx *gorm.DB, checkID uuid.UUID) error {
+	return tx.Exec(`
+		UPDATE checks SET next_run_at = (
+			SELECT MIN(crs.next_run_at)
+			FROM check_region_states crs
+			JOIN check_regions cr ON cr.check_id = crs.check_id AND cr.region_id = crs.region_id
+			WHERE crs.check_id = ?
+		)
+		WHERE id = ?
+	`, checkID, checkID).Error
+}
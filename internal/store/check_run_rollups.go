@@ -0,0 +1,186 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"pulse/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// rollupSelect aggregates the rolled up runs into buckets of the given resolution.
+// Status counts exclude runs during maintenance, like uptime, while latencies are
+// taken from all runs, like timings.
+func rollupSelect(resolution models.RollupResolution) string {
+	return fmt.Sprintf(`
+		INSERT INTO check_run_rollups (
+			check_id, region_id, resolution, bucket_start,
+			total_runs, passing, degraded, failing,
+			response_time_p50_us, response_time_p95_us, response_time_p99_us,
+			network_timings
+		)
+		SELECT
+			check_id,
+			region_id,
+			'%[1]s',
+			DATE_TRUNC('%[1]s', created_at, 'UTC') AS bucket,
+			COUNT(*) FILTER (WHERE NOT in_maintenance),
+			COUNT(*) FILTER (WHERE NOT in_maintenance AND status = 'passing'),
+			COUNT(*) FILTER (WHERE NOT in_maintenance AND status = 'degraded'),
+			COUNT(*) FILTER (WHERE NOT in_maintenance AND status = 'failing'),
+			PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY (network_timings->>'response_time_us')::float8)::integer,
+			PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY (network_timings->>'response_time_us')::float8)::integer,
+			PERCENTILE_CONT(0.99) WITHIN GROUP (ORDER BY (network_timings->>'response_time_us')::float8)::integer,
+			NULLIF(JSONB_STRIP_NULLS(JSONB_BUILD_OBJECT(
+				'dns_duration_us', AVG((network_timings->>'dns_duration_us')::float8)::integer,
+				'tcp_duration_us', AVG((network_timings->>'tcp_duration_us')::float8)::integer,
+				'tls_duration_us', AVG((network_timings->>'tls_duration_us')::float8)::integer,
+				'request_duration_us', AVG((network_timings->>'request_duration_us')::float8)::integer,
+				'ttfb_us', AVG((network_timings->>'ttfb_us')::float8)::integer,
+				'download_us', AVG((network_timings->>'download_us')::float8)::integer,
+				'response_time_us', AVG((network_timings->>'response_time_us')::float8)::integer
+			)), '{}'::jsonb)
+		FROM rolled
+		WHERE deleted_at IS NULL
+		GROUP BY check_id, region_id, bucket
+		ON CONFLICT (check_id, resolution, bucket_start, region_id) DO UPDATE SET
+			total_runs = check_run_rollups.total_runs + EXCLUDED.total_runs,
+			passing = check_run_rollups.passing + EXCLUDED.passing,
+			degraded = check_run_rollups.degraded + EXCLUDED.degraded,
+			failing = check_run_rollups.failing + EXCLUDED.failing,
+			response_time_p50_us = COALESCE(check_run_rollups.response_time_p50_us, EXCLUDED.response_time_p50_us),
+			response_time_p95_us = COALESCE(check_run_rollups.response_time_p95_us, EXCLUDED.response_time_p95_us),
+			response_time_p99_us = COALESCE(check_run_rollups.response_time_p99_us, EXCLUDED.response_time_p99_us),
+			network_timings = COALESCE(check_run_rollups.network_timings, EXCLUDED.network_timings),
+			updated_at = CURRENT_TIMESTAMP
+	`, resolution)
+}
+
+// RollupCheckRuns rolls the runs of a project's checks created within [start, end)
+// into hourly and daily rollups and deletes them, returning how many runs were
+// rolled up. The runs are deleted and aggregated by a single statement, so each
+// run is counted exactly once. Runs that alerts refer to are kept.
+//
+// A bucket is only rolled up again if runs were stored after it was: their counts
+// are added to the rollup, the latencies of the first rollup are kept.
+func (s *Store) RollupCheckRuns(projectID uuid.UUID, start, end time.Time) (int64, error) {
+	var count int64
+	err := s.db.Raw(fmt.Sprintf(`
+		WITH rolled AS (
+			DELETE FROM check_runs cr
+			USING checks c
+			WHERE c.id = cr.check_id
+				AND c.project_id = ?
+				AND cr.created_at >= ?
+				AND cr.created_at < ?
+				AND NOT EXISTS (SELECT 1 FROM alerts a WHERE a.run_id = cr.id)
+			RETURNING cr.check_id, cr.region_id, cr.created_at, cr.deleted_at, cr.status,
+				cr.in_maintenance, cr.network_timings
+		),
+		hourly AS (%s),
+		daily AS (%s)
+		SELECT COUNT(*) FROM rolled
+	`, rollupSelect(models.RollupResolutionHour), rollupSelect(models.RollupResolutionDay)),
+		projectID, start, end).Scan(&count).Error
+	return count, err
+}
+
+// GetOldestRollableCheckRun returns when the oldest run of a project's checks that
+// was created before the given time and can be rolled up was created, or nil if
+// there is none.
+func (s *Store) GetOldestRollableCheckRun(projectID uuid.UUID, before time.Time) (*time.Time, error) {
+	var result struct {
+		MinTime *time.Time `gorm:"column:min_time"`
+	}
+	err := s.db.Raw(`
+		SELECT MIN(cr.created_at) AS min_time
+		FROM check_runs cr
+		JOIN checks c ON c.id = cr.check_id
+		WHERE c.project_id = ?
+			AND cr.created_at < ?
+			AND NOT EXISTS (SELECT 1 FROM alerts a WHERE a.run_id = cr.id)
+	`, projectID, before).Scan(&result).Error
+	return result.MinTime, err
+}
+
+// DeleteHourlyRollups deletes the hourly rollups of a project's checks that start
+// before the given time. The daily rollups covering them are kept.
+func (s *Store) DeleteHourlyRollups(projectID uuid.UUID, before time.Time) (int64, error) {
+	result := s.db.Exec(`
+		DELETE FROM check_run_rollups r
+		USING checks c
+		WHERE c.id = r.check_id
+			AND c.project_id = ?
+			AND r.resolution = ?
+			AND r.bucket_start < ?
+	`, projectID, models.RollupResolutionHour, before)
+	return result.RowsAffected, result.Error
+}
+
+// rollupsInRange selects the rollups of a check overlapping [start, end]. Daily
+// rollups cover the same runs as the hourly rollups of their day, so they are only
+// used once those were deleted.
+const rollupsInRange = `
+	FROM check_run_rollups r
+	WHERE r.check_id = ?
+		AND r.bucket_start + ('1 ' || r.resolution)::interval > ?
+		AND r.bucket_start <= ?
+		AND (r.resolution = 'hour' OR NOT EXISTS (
+			SELECT 1 FROM check_run_rollups h
+			WHERE h.check_id = r.check_id
+				AND h.region_id = r.region_id
+				AND h.resolution = 'hour'
+				AND h.bucket_start >= r.bucket_start
+				AND h.bucket_start < r.bucket_start + INTERVAL '1 day'
+		))
+`
+
+// getRollupRange returns the start of the first and last rollup of a check
+// overlapping the time range, or nil, nil if there are none.
+func (s *Store) getRollupRange(checkID uuid.UUID, startTime, endTime time.Time) (*time.Time, *time.Time, error) {
+	var result struct {
+		MinTime *time.Time `gorm:"column:min_time"`
+		MaxTime *time.Time `gorm:"column:max_time"`
+	}
+	err := s.db.Raw(`SELECT MIN(r.bucket_start) AS min_time, MAX(r.bucket_start) AS max_time`+rollupsInRange,
+		checkID, startTime, endTime).Scan(&result).Error
+	return result.MinTime, result.MaxTime, err
+}
+
+// rollupUptime is the status counts of a check's rollups in a time bucket
+type rollupUptime struct {
+	Timestamp time.Time `gorm:"column:time_bucket"`
+	TotalRuns int       `gorm:"column:total_runs"`
+	Passing   int       `gorm:"column:passing"`
+	Degraded  int       `gorm:"column:degraded"`
+	Failing   int       `gorm:"column:failing"`
+}
+
+// getRollupUptime sums the status counts of a check's rollups per time bucket.
+// Buckets finer than a rollup hold its counts at the rollup's start.
+func (s *Store) getRollupUptime(checkID uuid.UUID, startTime, endTime time.Time, timeBucket string) ([]rollupUptime, error) {
+	var results []rollupUptime
+	err := s.db.Raw(`
+		SELECT
+			DATE_TRUNC(?, r.bucket_start) AS time_bucket,
+			SUM(r.total_runs) AS total_runs,
+			SUM(r.passing) AS passing,
+			SUM(r.degraded) AS degraded,
+			SUM(r.failing) AS failing
+	`+rollupsInRange+`
+		GROUP BY time_bucket
+	`, timeBucket, checkID, startTime, endTime).Scan(&results).Error
+	return results, err
+}
+
+// getRollupTimings returns the average network timings and response time
+// percentiles of each rollup of a check, as timing data points.
+func (s *Store) getRollupTimings(checkID uuid.UUID, startTime, endTime time.Time) ([]models.CheckRunRollup, error) {
+	var rollups []models.CheckRunRollup
+	err := s.db.Raw(`SELECT r.*`+rollupsInRange+`
+		AND r.network_timings IS NOT NULL
+		ORDER BY r.bucket_start ASC
+	`, checkID, startTime, endTime).Scan(&rollups).Error
+	return rollups, err
+}
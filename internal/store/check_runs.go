@@ -9,10 +9,18 @@ import (
 
 	"github.com/google/uuid"
 	"gorm.io/datatypes"
+	"gorm.io/gorm"
 )
 
+// CreateCheckRun stores a run and adds it to its check's stats
 func (s *Store) CreateCheckRun(run *models.CheckRun) (*models.CheckRun, error) {
-	if err := s.db.Create(run).Error; err != nil {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(run).Error; err != nil {
+			return err
+		}
+		return recordCheckStats(tx, run)
+	})
+	if err != nil {
 		return nil, err
 	}
 	return run, nil
@@ -26,6 +34,61 @@ func (s *Store) GetCheckRun(id uuid.UUID) (*models.CheckRun, error) {
 	return &run, nil
 }
 
+// GetCheckRunsByIDs returns the runs of a check among ids that have been stored
+func (s *Store) GetCheckRunsByIDs(checkID uuid.UUID, ids []uuid.UUID) ([]models.CheckRun, error) {
+	var runs []models.CheckRun
+	if err := s.db.Preload("Region").Where("check_id = ? AND id IN ?", checkID, ids).Find(&runs).Error; err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// GetLatestCheckRunsByProject returns the latest run of each check of a project in
+// each of its regions, with the region loaded.
+func (s *Store) GetLatestCheckRunsByProject(projectID uuid.UUID) ([]models.CheckRun, error) {
+	var runs []models.CheckRun
+	if err := s.db.Preload("Region").Where(`id IN (
+		SELECT latest.id
+		FROM check_region_states crs
+		JOIN checks c ON c.id = crs.check_id
+		CROSS JOIN LATERAL (
+			SELECT id FROM check_runs
+			WHERE check_id = crs.check_id AND region_id = crs.region_id AND deleted_at IS NULL
+			ORDER BY created_at DESC, id DESC
+			LIMIT 1
+		) latest
+		WHERE c.project_id = ? AND c.deleted_at IS NULL
+	)`, projectID).Find(&runs).Error; err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// GetRecentCheckRunsByRegion returns up to limit of the latest runs of a check per region,
+// newest first. Runs during maintenance are skipped.
+func (s *Store) GetRecentCheckRunsByRegion(checkID uuid.UUID, limit int) (map[uuid.UUID][]models.CheckRun, error) {
+	var runs []models.CheckRun
+	if err := s.db.Raw(`
+		SELECT id, status, failure_reason, region_id, check_id, created_at
+		FROM (
+			SELECT id, status, failure_reason, region_id, check_id, created_at,
+				ROW_NUMBER() OVER (PARTITION BY region_id ORDER BY created_at DESC, id DESC) AS rn
+			FROM check_runs
+			WHERE check_id = ? AND deleted_at IS NULL AND in_maintenance = false
+		) ranked
+		WHERE rn <= ?
+		ORDER BY region_id, created_at DESC, id DESC
+	`, checkID, limit).Scan(&runs).Error; err != nil {
+		return nil, err
+	}
+
+	byRegion := make(map[uuid.UUID][]models.CheckRun)
+	for _, run := range runs {
+		byRegion[run.RegionID] = append(byRegion[run.RegionID], run)
+	}
+	return byRegion, nil
+}
+
 func (s *Store) GetCheckRunsByCheck(checkID uuid.UUID, limit int, after, before *uuid.UUID, startTime, endTime *time.Time) ([]models.CheckRun, error) {
 	var runs []models.CheckRun
 	query := s.db.Preload("Region").Omit("Check").Where("check_id = ?", checkID)
@@ -92,6 +155,8 @@ type UptimeDataPoint struct {
 // GetCheckUptimeData returns aggregated uptime data for a check over a specified time range
 // startTime and endTime define the time range (inclusive)
 // timeBucket determines the aggregation interval: "second", "minute", "hour", "day", or "week"
+// Runs during maintenance windows are excluded
+// Runs past their project's retention are read from their rollups
 func (s *Store) GetCheckUptimeData(checkID uuid.UUID, startTime, endTime time.Time, timeBucket string) ([]UptimeDataPoint, error) {
 	// Validate time bucket
 	if timeBucket != "second" && timeBucket != "minute" && timeBucket != "hour" && timeBucket != "day" && timeBucket != "week" {
@@ -116,6 +181,7 @@ func (s *Store) GetCheckUptimeData(checkID uuid.UUID, startTime, endTime time.Ti
 			AND created_at >= ?
 			AND created_at <= ?
 			AND deleted_at IS NULL
+			AND in_maintenance = false
 		GROUP BY time_bucket, status
 		ORDER BY time_bucket ASC
 	`, timeBucket, checkID, startTime, endTime).Scan(&results).Error
@@ -124,6 +190,20 @@ func (s *Store) GetCheckUptimeData(checkID uuid.UUID, startTime, endTime time.Ti
 		return nil, err
 	}
 
+	rollups, err := s.getRollupUptime(checkID, startTime, endTime, timeBucket)
+	if err != nil {
+		return nil, err
+	}
+	for _, rollup := range rollups {
+		results = append(results,
+			Result{Timestamp: rollup.Timestamp, Status: string(models.CheckRunStatusPassing), Count: rollup.Passing},
+			Result{Timestamp: rollup.Timestamp, Status: string(models.CheckRunStatusDegraded), Count: rollup.Degraded},
+			Result{Timestamp: rollup.Timestamp, Status: string(models.CheckRunStatusFailing), Count: rollup.Failing},
+			// Runs of other statuses only count towards the total
+			Result{Timestamp: rollup.Timestamp, Count: rollup.TotalRuns - rollup.Passing - rollup.Degraded - rollup.Failing},
+		)
+	}
+
 	// Group results by time bucket and calculate uptime
 	bucketMap := make(map[time.Time]*UptimeDataPoint)
 
@@ -217,14 +297,17 @@ func DetermineTimeBucket(startTime, endTime time.Time) string {
 }
 
 // TimingDataPoint represents a single timing data point from a check run
+// Points of rollups have no run and hold the average timings of the rollup's runs
+// and their response time percentiles.
 type TimingDataPoint struct {
-	RunID          uuid.UUID              `json:"run_id"`
-	Timestamp      time.Time              `json:"timestamp"`
-	NetworkTimings map[string]interface{} `json:"network_timings"`
+	RunID          uuid.UUID               `json:"run_id"`
+	Resolution     models.RollupResolution `json:"resolution,omitempty"`
+	Timestamp      time.Time               `json:"timestamp"`
+	NetworkTimings map[string]interface{}  `json:"network_timings"`
 }
 
 // GetCheckRunsDataRange returns the actual data range (first and last timestamps) for check runs
-// within the specified time range. Returns nil, nil if no data exists.
+// within the specified time range, rollups included. Returns nil, nil if no data exists.
 func (s *Store) GetCheckRunsDataRange(checkID uuid.UUID, startTime, endTime time.Time) (*time.Time, *time.Time, error) {
 	var result struct {
 		MinTime time.Time `gorm:"column:min_time"`
@@ -246,17 +329,29 @@ func (s *Store) GetCheckRunsDataRange(checkID uuid.UUID, startTime, endTime time
 		return nil, nil, err
 	}
 
-	// Check if we got any data (zero time means no data)
-	if result.MinTime.IsZero() || result.MaxTime.IsZero() {
+	minTime, maxTime, err := s.getRollupRange(checkID, startTime, endTime)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !result.MinTime.IsZero() && (minTime == nil || result.MinTime.Before(*minTime)) {
+		minTime = &result.MinTime
+	}
+	if !result.MaxTime.IsZero() && (maxTime == nil || result.MaxTime.After(*maxTime)) {
+		maxTime = &result.MaxTime
+	}
+
+	// Check if we got any data
+	if minTime == nil || maxTime == nil {
 		return nil, nil, nil
 	}
 
-	return &result.MinTime, &result.MaxTime, nil
+	return minTime, maxTime, nil
 }
 
 // GetCheckTimingsData returns timing data for all check runs within a specified time range
 // startTime and endTime define the time range (inclusive)
-// Returns a list of timing data points, one per check run
+// Returns a list of timing data points, one per check run, and one per rollup for runs
+// past their project's retention
 func (s *Store) GetCheckTimingsData(checkID uuid.UUID, startTime, endTime time.Time) ([]TimingDataPoint, error) {
 	var runs []struct {
 		ID             uuid.UUID      `gorm:"column:id"`
@@ -278,7 +373,33 @@ func (s *Store) GetCheckTimingsData(checkID uuid.UUID, startTime, endTime time.T
 		return nil, err
 	}
 
-	dataPoints := make([]TimingDataPoint, 0, len(runs))
+	rollups, err := s.getRollupTimings(checkID, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+
+	dataPoints := make([]TimingDataPoint, 0, len(rollups)+len(runs))
+	for _, rollup := range rollups {
+		var timings map[string]interface{}
+		if err := json.Unmarshal(rollup.NetworkTimings, &timings); err != nil || len(timings) == 0 {
+			continue
+		}
+		for key, value := range map[string]*int{
+			"response_time_p50_us": rollup.ResponseTimeP50Us,
+			"response_time_p95_us": rollup.ResponseTimeP95Us,
+			"response_time_p99_us": rollup.ResponseTimeP99Us,
+		} {
+			if value != nil {
+				timings[key] = *value
+			}
+		}
+		dataPoints = append(dataPoints, TimingDataPoint{
+			Resolution:     rollup.Resolution,
+			Timestamp:      rollup.BucketStart,
+			NetworkTimings: timings,
+		})
+	}
+
 	for _, run := range runs {
 		// Unmarshal the JSONB data
 		var timings map[string]interface{}
@@ -293,5 +414,10 @@ func (s *Store) GetCheckTimingsData(checkID uuid.UUID, startTime, endTime time.T
 		}
 	}
 
+	// Runs that alerts refer to are never rolled up, so they can be older than rollups
+	sort.SliceStable(dataPoints, func(i, j int) bool {
+		return dataPoints[i].Timestamp.Before(dataPoints[j].Timestamp)
+	})
+
 	return dataPoints, nil
 }
@@ -0,0 +1,34 @@
+package store
+
+import (
+	"time"
+
+	"pulse/internal/models"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// recordCheckStats adds a stored run to its check's stats. The stats row is locked
+// while it is updated, so the runs of a check's regions are all counted.
+func recordCheckStats(tx *gorm.DB, run *models.CheckRun) error {
+	// The first run of a check creates its stats, concurrent runs wait for it
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.CheckStats{
+		CheckID:       run.CheckID,
+		RecentRuns:    datatypes.JSON("[]"),
+		HourlyBuckets: datatypes.JSON("[]"),
+	}).Error; err != nil {
+		return err
+	}
+
+	var stats models.CheckStats
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("check_id = ?", run.CheckID).Take(&stats).Error; err != nil {
+		return err
+	}
+
+	if err := stats.Record(run, time.Now().UTC()); err != nil {
+		return err
+	}
+	return tx.Save(&stats).Error
+}
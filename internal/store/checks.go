@@ -1,8 +1,6 @@
 package store
 
 import (
-	"time"
-
 	"pulse/internal/models"
 
 	"github.com/google/uuid"
@@ -14,7 +12,7 @@ func (s *Store) CreateCheck(check *models.Check) error {
 
 func (s *Store) GetCheck(id uuid.UUID) (*models.Check, error) {
 	var check models.Check
-	if err := s.db.Preload("Project").Preload("Tags").Preload("Regions").First(&check, "id = ?", id).Error; err != nil {
+	if err := s.db.Preload("Project").Preload("Tags").Preload("Regions").Preload("Channels").First(&check, "id = ?", id).Error; err != nil {
 		return nil, err
 	}
 	return &check, nil
@@ -36,34 +34,14 @@ func (s *Store) DeleteCheck(id uuid.UUID) error {
 	return s.db.Delete(&models.Check{}, "id = ?", id).Error
 }
 
-func (s *Store) GetDueChecks(regionCode string) ([]models.Check, error) {
-	var checks []models.Check
-	now := time.Now().UTC()
-
-	// Get region ID by code
-	var region models.Region
-	if err := s.db.Where("code = ?", regionCode).First(&region).Error; err != nil {
-		return nil, err
+// TransitionCheckAlertStatus moves the check's alerted status from one value to another.
+// It returns false when another run already changed it, so only one caller alerts.
+func (s *Store) TransitionCheckAlertStatus(checkID uuid.UUID, from, to models.CheckRunStatus) (bool, error) {
+	result := s.db.Model(&models.Check{}).
+		Where("id = ? AND alert_status = ?", checkID, string(from)).
+		Update("alert_status", string(to))
+	if result.Error != nil {
+		return false, result.Error
 	}
-
-	// Get checks that are due and have this region enabled
-	// Using a join to filter by region
-	if err := s.db.
-		Preload("Regions").
-		Joins("JOIN check_regions ON checks.id = check_regions.check_id").
-		Where("checks.is_enabled = ? AND check_regions.region_id = ? AND (checks.next_run_at IS NULL OR checks.next_run_at <= ?)", true, region.ID, now).
-		Find(&checks).Error; err != nil {
-		return nil, err
-	}
-
-	return checks, nil
-}
-
-func (s *Store) UpdateCheckStatus(checkID uuid.UUID, nextRun time.Time, lastStatus models.CheckRunStatus) error {
-	now := time.Now().UTC()
-	return s.db.Model(&models.Check{}).Where("id = ?", checkID).Updates(map[string]interface{}{
-		"last_run_at": now,
-		"next_run_at": nextRun,
-		"last_status": string(lastStatus),
-	}).Error
+	return result.RowsAffected > 0, nil
 }
@@ -0,0 +1,33 @@
+package store
+
+import (
+	"time"
+
+	"pulse/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// GetCheckByHeartbeatToken loads the heartbeat check a ping URL belongs to
+func (s *Store) GetCheckByHeartbeatToken(token string) (*models.Check, error) {
+	var check models.Check
+	if err := s.db.Preload("Project").Preload("Regions").Preload("Channels").
+		First(&check, "heartbeat_token = ? AND type = ?", token, models.CheckTypeHeartbeat).Error; err != nil {
+		return nil, err
+	}
+	return &check, nil
+}
+
+// StartHeartbeat records that the monitored job signalled its start
+func (s *Store) StartHeartbeat(checkID uuid.UUID, startedAt time.Time) error {
+	return s.db.Model(&models.Check{}).Where("id = ?", checkID).
+		Update("heartbeat_started_at", startedAt).Error
+}
+
+// RecordHeartbeatPing stores a completed ping, clearing any pending start
+func (s *Store) RecordHeartbeatPing(checkID uuid.UUID, pingedAt time.Time) error {
+	return s.db.Model(&models.Check{}).Where("id = ?", checkID).Updates(map[string]interface{}{
+		"last_ping_at":         pingedAt,
+		"heartbeat_started_at": nil,
+	}).Error
+}
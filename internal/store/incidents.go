@@ -0,0 +1,117 @@
+package store
+
+import (
+	"time"
+
+	"pulse/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (s *Store) CreateIncident(incident *models.Incident) error {
+	return s.db.Create(incident).Error
+}
+
+// GetIncident returns an incident with its alerts and timeline, oldest first
+func (s *Store) GetIncident(id uuid.UUID) (*models.Incident, error) {
+	var incident models.Incident
+	if err := s.db.
+		Preload("Check").
+		Preload("Assignee").
+		Preload("AcknowledgedBy").
+		Preload("ResolvedBy").
+		Preload("Alerts", func(db *gorm.DB) *gorm.DB {
+			return db.Preload("Region").Order("created_at ASC, id ASC")
+		}).
+		Preload("Events", func(db *gorm.DB) *gorm.DB {
+			return db.Preload("User").Order("created_at ASC, id ASC")
+		}).
+		First(&incident, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &incident, nil
+}
+
+// GetOpenIncidentByCheck returns the unresolved incident of a check, or nil if there is none
+func (s *Store) GetOpenIncidentByCheck(checkID uuid.UUID) (*models.Incident, error) {
+	var incidents []models.Incident
+	if err := s.db.Where("check_id = ? AND resolved_at IS NULL", checkID).Limit(1).Find(&incidents).Error; err != nil {
+		return nil, err
+	}
+	if len(incidents) == 0 {
+		return nil, nil
+	}
+	return &incidents[0], nil
+}
+
+// GetIncidentsByProject returns the latest incidents of a project, optionally filtered by status
+func (s *Store) GetIncidentsByProject(projectID uuid.UUID, status *models.IncidentStatus, limit int) ([]models.Incident, error) {
+	var incidents []models.Incident
+	query := s.db.Preload("Check").Preload("Assignee").Where("project_id = ?", projectID)
+	if status != nil {
+		query = query.Where("status = ?", string(*status))
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Order("started_at DESC, id DESC").Find(&incidents).Error; err != nil {
+		return nil, err
+	}
+	return incidents, nil
+}
+
+// GetIncidentsByCheck returns the latest incidents of a check
+func (s *Store) GetIncidentsByCheck(checkID uuid.UUID, limit int) ([]models.Incident, error) {
+	var incidents []models.Incident
+	query := s.db.Preload("Assignee").Where("check_id = ?", checkID)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Order("started_at DESC, id DESC").Find(&incidents).Error; err != nil {
+		return nil, err
+	}
+	return incidents, nil
+}
+
+// UpdateOpenIncident updates the given columns of an unresolved incident. It returns
+// false if the incident was resolved in the meantime.
+func (s *Store) UpdateOpenIncident(id uuid.UUID, updates map[string]interface{}) (bool, error) {
+	result := s.db.Model(&models.Incident{}).
+		Where("id = ? AND resolved_at IS NULL", id).
+		Updates(updates)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// SetIncidentSeverity updates the severity of an incident, leaving the fields users
+// change alone
+func (s *Store) SetIncidentSeverity(id uuid.UUID, severity models.CheckRunStatus) error {
+	return s.db.Model(&models.Incident{}).Where("id = ?", id).Update("severity", severity).Error
+}
+
+// ResolveIncident resolves an incident on recovery. It returns false if the
+// incident was already resolved.
+func (s *Store) ResolveIncident(id uuid.UUID, resolvedAt time.Time) (bool, error) {
+	result := s.db.Model(&models.Incident{}).
+		Where("id = ? AND resolved_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"status":      models.IncidentStatusResolved,
+			"resolved_at": resolvedAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (s *Store) CreateIncidentEvent(event *models.IncidentEvent) error {
+	return s.db.Omit(clause.Associations).Create(event).Error
+}
+
+func (s *Store) SetAlertIncident(alertID, incidentID uuid.UUID) error {
+	return s.db.Model(&models.Alert{}).Where("id = ?", alertID).Update("incident_id", incidentID).Error
+}
@@ -0,0 +1,147 @@
+package store
+
+import (
+	"errors"
+	"time"
+
+	"pulse/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrMaintenanceTargetNotFound is returned when a check or tag a maintenance window
+// targets doesn't exist in the window's project
+var ErrMaintenanceTargetNotFound = errors.New("maintenance window target not found")
+
+// CreateMaintenanceWindow creates a window with the checks and tags it targets
+func (s *Store) CreateMaintenanceWindow(window *models.MaintenanceWindow, checkIDs, tagIDs []uuid.UUID) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Checks", "Tags").Create(window).Error; err != nil {
+			return err
+		}
+		return setMaintenanceWindowTargets(tx, window, checkIDs, tagIDs)
+	})
+}
+
+func (s *Store) GetMaintenanceWindow(id uuid.UUID) (*models.MaintenanceWindow, error) {
+	var window models.MaintenanceWindow
+	if err := s.db.Preload("Checks").Preload("Tags").First(&window, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &window, nil
+}
+
+func (s *Store) GetMaintenanceWindowsByProject(projectID uuid.UUID) ([]models.MaintenanceWindow, error) {
+	var windows []models.MaintenanceWindow
+	if err := s.db.Preload("Checks").Preload("Tags").Where("project_id = ?", projectID).Order("starts_at DESC").Find(&windows).Error; err != nil {
+		return nil, err
+	}
+	return windows, nil
+}
+
+// UpdateMaintenanceWindow saves a window and replaces the checks and tags it targets
+func (s *Store) UpdateMaintenanceWindow(window *models.MaintenanceWindow, checkIDs, tagIDs []uuid.UUID) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Checks", "Tags").Save(window).Error; err != nil {
+			return err
+		}
+		return setMaintenanceWindowTargets(tx, window, checkIDs, tagIDs)
+	})
+}
+
+func (s *Store) DeleteMaintenanceWindow(id uuid.UUID) error {
+	return s.db.Delete(&models.MaintenanceWindow{}, "id = ?", id).Error
+}
+
+// setMaintenanceWindowTargets replaces the checks and tags a window targets. A window
+// without targets covers its whole project, so IDs outside the project fail with
+// ErrMaintenanceTargetNotFound rather than being dropped.
+func setMaintenanceWindowTargets(tx *gorm.DB, window *models.MaintenanceWindow, checkIDs, tagIDs []uuid.UUID) error {
+	checkIDs, tagIDs = uniqueIDs(checkIDs), uniqueIDs(tagIDs)
+
+	checks := []models.Check{}
+	if len(checkIDs) > 0 {
+		if err := tx.Where("id IN ? AND project_id = ?", checkIDs, window.ProjectID).Find(&checks).Error; err != nil {
+			return err
+		}
+	}
+	if len(checks) != len(checkIDs) {
+		return ErrMaintenanceTargetNotFound
+	}
+	if err := tx.Model(window).Association("Checks").Replace(checks); err != nil {
+		return err
+	}
+
+	tags := []models.Tag{}
+	if len(tagIDs) > 0 {
+		if err := tx.Where("id IN ? AND project_id = ?", tagIDs, window.ProjectID).Find(&tags).Error; err != nil {
+			return err
+		}
+	}
+	if len(tags) != len(tagIDs) {
+		return ErrMaintenanceTargetNotFound
+	}
+	return tx.Model(window).Association("Tags").Replace(tags)
+}
+
+// uniqueIDs returns ids without duplicates, in their original order
+func uniqueIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	unique := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// GetMaintenanceWindowsForCheck returns the windows that may apply to a check at t:
+// windows targeting its project as a whole, one of its tags, or the check itself.
+// Recurring windows are returned regardless of whether an occurrence covers t.
+func (s *Store) GetMaintenanceWindowsForCheck(checkID, projectID uuid.UUID, t time.Time) ([]models.MaintenanceWindow, error) {
+	var windows []models.MaintenanceWindow
+	if err := s.db.Raw(`
+		SELECT mw.*
+		FROM maintenance_windows mw
+		WHERE mw.project_id = ?
+			AND mw.deleted_at IS NULL
+			AND mw.starts_at <= ?
+			AND (mw.rrule IS NOT NULL OR mw.ends_at > ?)
+			AND (
+				(
+					NOT EXISTS (SELECT 1 FROM maintenance_window_checks mwc WHERE mwc.maintenance_window_id = mw.id)
+					AND NOT EXISTS (SELECT 1 FROM maintenance_window_tags mwt WHERE mwt.maintenance_window_id = mw.id)
+				)
+				OR EXISTS (
+					SELECT 1 FROM maintenance_window_checks mwc
+					WHERE mwc.maintenance_window_id = mw.id AND mwc.check_id = ?
+				)
+				OR EXISTS (
+					SELECT 1 FROM maintenance_window_tags mwt
+					JOIN check_tags ct ON ct.tag_id = mwt.tag_id
+					WHERE mwt.maintenance_window_id = mw.id AND ct.check_id = ?
+				)
+			)
+	`, projectID, t, t, checkID, checkID).Scan(&windows).Error; err != nil {
+		return nil, err
+	}
+	return windows, nil
+}
+
+// IsCheckInMaintenance reports whether any maintenance window covers the check at t
+func (s *Store) IsCheckInMaintenance(checkID, projectID uuid.UUID, t time.Time) (bool, error) {
+	windows, err := s.GetMaintenanceWindowsForCheck(checkID, projectID, t)
+	if err != nil {
+		return false, err
+	}
+
+	for i := range windows {
+		if windows[i].IsActiveAt(t) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,71 @@
+package store
+
+import (
+	"pulse/internal/models"
+
+	"github.com/google/uuid"
+)
+
+func (s *Store) CreateNotificationChannel(channel *models.NotificationChannel) error {
+	return s.db.Create(channel).Error
+}
+
+func (s *Store) GetNotificationChannel(id uuid.UUID) (*models.NotificationChannel, error) {
+	var channel models.NotificationChannel
+	if err := s.db.First(&channel, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &channel, nil
+}
+
+func (s *Store) GetNotificationChannelsByProject(projectID uuid.UUID) ([]models.NotificationChannel, error) {
+	var channels []models.NotificationChannel
+	if err := s.db.Where("project_id = ?", projectID).Order("name").Find(&channels).Error; err != nil {
+		return nil, err
+	}
+	return channels, nil
+}
+
+// GetNotificationChannelsByCheck returns the enabled channels a check is subscribed to
+func (s *Store) GetNotificationChannelsByCheck(checkID uuid.UUID) ([]models.NotificationChannel, error) {
+	var channels []models.NotificationChannel
+	if err := s.db.
+		Joins("JOIN check_channels ON notification_channels.id = check_channels.notification_channel_id").
+		Where("check_channels.check_id = ? AND notification_channels.is_enabled = ?", checkID, true).
+		Find(&channels).Error; err != nil {
+		return nil, err
+	}
+	return channels, nil
+}
+
+func (s *Store) UpdateNotificationChannel(channel *models.NotificationChannel) error {
+	return s.db.Save(channel).Error
+}
+
+func (s *Store) DeleteNotificationChannel(id uuid.UUID) error {
+	return s.db.Delete(&models.NotificationChannel{}, "id = ?", id).Error
+}
+
+func (s *Store) AddChannelToCheck(checkID uuid.UUID, channelID uuid.UUID) error {
+	var check models.Check
+	if err := s.db.First(&check, "id = ?", checkID).Error; err != nil {
+		return err
+	}
+	var channel models.NotificationChannel
+	if err := s.db.Where("id = ? AND project_id = ?", channelID, check.ProjectID).First(&channel).Error; err != nil {
+		return err
+	}
+	return s.db.Model(&check).Association("Channels").Append(&channel)
+}
+
+func (s *Store) RemoveChannelFromCheck(checkID uuid.UUID, channelID uuid.UUID) error {
+	var check models.Check
+	if err := s.db.First(&check, "id = ?", checkID).Error; err != nil {
+		return err
+	}
+	var channel models.NotificationChannel
+	if err := s.db.First(&channel, "id = ?", channelID).Error; err != nil {
+		return err
+	}
+	return s.db.Model(&check).Association("Channels").Delete(&channel)
+}
@@ -42,3 +42,17 @@ func (s *Store) UpdateProject(project *models.Project) error {
 func (s *Store) DeleteProject(id uuid.UUID) error {
 	return s.db.Delete(&models.Project{}, "id = ?", id).Error
 }
+
+// GetProjectByMetricsToken returns the project whose probe metrics the token scrapes
+func (s *Store) GetProjectByMetricsToken(token string) (*models.Project, error) {
+	var project models.Project
+	if err := s.db.First(&project, "metrics_token = ?", token).Error; err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+// SetProjectMetricsToken replaces the metrics token of a project; nil disables scrapes
+func (s *Store) SetProjectMetricsToken(projectID uuid.UUID, token *string) error {
+	return s.db.Model(&models.Project{}).Where("id = ?", projectID).Update("metrics_token", token).Error
+}
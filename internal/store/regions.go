@@ -15,6 +15,14 @@ func (s *Store) ListRegions() ([]models.Region, error) {
 	return regions, nil
 }
 
+func (s *Store) GetRegion(id uuid.UUID) (*models.Region, error) {
+	var region models.Region
+	if err := s.db.First(&region, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &region, nil
+}
+
 func (s *Store) GetRegionByCode(code string) (*models.Region, error) {
 	var region models.Region
 	if err := s.db.Where("code = ?", code).First(&region).Error; err != nil {
@@ -44,5 +52,8 @@ func (s *Store) RemoveRegionFromCheck(checkID uuid.UUID, regionID uuid.UUID) err
 	if err := s.db.First(&region, "id = ?", regionID).Error; err != nil {
 		return err
 	}
-	return s.db.Model(&check).Association("Regions").Delete(&region)
+	if err := s.db.Model(&check).Association("Regions").Delete(&region); err != nil {
+		return err
+	}
+	return s.db.Where("check_id = ? AND region_id = ?", checkID, regionID).Delete(&models.CheckRegionState{}).Error
 }
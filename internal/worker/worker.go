@@ -2,89 +2,410 @@ package worker
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"math"
+	"os"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 
 	"pulse/internal/alerter"
 	"pulse/internal/checker"
+	"pulse/internal/clickhouse"
 	"pulse/internal/metrics"
 	"pulse/internal/models"
 	"pulse/internal/redis"
 	"pulse/internal/store"
 )
 
+const (
+	// reapInterval is how often expired jobs of the region are requeued
+	reapInterval = 30 * time.Second
+	// leaseInterval is how often the lease of a job being processed is extended.
+	// Runs can outlast the visibility timeout with retries, so they keep it fresh.
+	leaseInterval = redis.JobVisibilityTimeout / 3
+	// scaleInterval is how often the pool size is adjusted to the queue depth
+	scaleInterval = 15 * time.Second
+	// drainTarget is how long the pool should take to work off the queued jobs
+	drainTarget = 30 * time.Second
+	// defaultRunDuration is assumed for runs until some were timed
+	defaultRunDuration = time.Second
+	// runDurationWeight is the weight of a new run in the average run duration
+	runDurationWeight = 0.2
+
+	defaultMinWorkers    = 3
+	defaultMaxWorkers    = 20
+	defaultShutdownGrace = 30 * time.Second
+)
+
+// errRunCancelled is returned for runs cut short by shutdown, whose jobs are
+// released rather than retried
+var errRunCancelled = errors.New("run cancelled")
+
+// Config holds the worker pool configuration.
+type Config struct {
+	// MinWorkers run at all times; the pool grows up to MaxWorkers while jobs
+	// queue up faster than it works them off.
+	MinWorkers int
+	MaxWorkers int
+	// ShutdownGrace is how long in-flight runs may finish on Stop before they
+	// are cancelled.
+	ShutdownGrace time.Duration
+}
+
+// DefaultConfig returns a Config with sensible defaults.
+func DefaultConfig() *Config {
+	return &Config{
+		MinWorkers:    defaultMinWorkers,
+		MaxWorkers:    defaultMaxWorkers,
+		ShutdownGrace: defaultShutdownGrace,
+	}
+}
+
+// slot is a worker goroutine of the pool. Slots are numbered from 0 and reused,
+// so the consumer names of a process stay bounded as the pool scales.
+type slot struct {
+	stop     chan struct{}
+	stopping bool
+}
+
 type Worker struct {
-	store       *store.Store
-	redis       *redis.Client
-	alerter     *alerter.Alerter
-	workerCount int
-	regionID    uuid.UUID
-	quit        chan struct{}
-	wg          sync.WaitGroup
+	store      *store.Store
+	redis      *redis.Client
+	alerter    *alerter.Alerter
+	runs       *clickhouse.Writer
+	config     *Config
+	regionID   uuid.UUID
+	regionCode string
+	ctx        context.Context
+	cancel     context.CancelFunc
+	quit       chan struct{}
+	wg         sync.WaitGroup
+
+	mu             sync.Mutex
+	slots          map[int]*slot
+	avgRunDuration time.Duration
 }
 
-func New(s *store.Store, r *redis.Client, a *alerter.Alerter, workerCount int, regionID uuid.UUID) *Worker {
+// New creates a worker pool for a region. Stored runs are also written to runs
+// when it is set.
+func New(s *store.Store, r *redis.Client, a *alerter.Alerter, region *models.Region, config *Config, runs *clickhouse.Writer) *Worker {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	if config.MinWorkers <= 0 {
+		config.MinWorkers = defaultMinWorkers
+	}
+
+	// A maximum below the minimum disables autoscaling
+	if config.MaxWorkers < config.MinWorkers {
+		config.MaxWorkers = config.MinWorkers
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
 	return &Worker{
-		store:       s,
-		redis:       r,
-		alerter:     a,
-		workerCount: workerCount,
-		regionID:    regionID,
-		quit:        make(chan struct{}),
+		store:      s,
+		redis:      r,
+		alerter:    a,
+		runs:       runs,
+		config:     config,
+		regionID:   region.ID,
+		regionCode: region.Code,
+		ctx:        ctx,
+		cancel:     cancel,
+		quit:       make(chan struct{}),
+		slots:      make(map[int]*slot),
 	}
 }
 
 func (w *Worker) Start() {
-	log.Printf("Starting %d workers for region %s...", w.workerCount, w.regionID)
-	for i := 0; i < w.workerCount; i++ {
+	log.Printf("Starting %d to %d workers for region %s...", w.config.MinWorkers, w.config.MaxWorkers, w.regionCode)
+
+	w.mu.Lock()
+	for i := 0; i < w.config.MinWorkers; i++ {
+		w.addWorker()
+	}
+	w.mu.Unlock()
+
+	metrics.RegisterQueueDepth(w.regionCode, func() (int64, error) {
+		return w.redis.GetQueueDepth(w.regionCode)
+	})
+
+	w.wg.Add(1)
+	go w.reap()
+
+	if w.config.MaxWorkers > w.config.MinWorkers {
 		w.wg.Add(1)
-		go w.work(i)
+		go w.autoscale()
 	}
 }
 
+// Stop stops dequeuing and waits for in-flight runs to be recorded. Runs still
+// going after the shutdown grace period are cancelled and their jobs released.
 func (w *Worker) Stop() {
 	log.Println("Stopping workers...")
 	close(w.quit)
-	w.wg.Wait()
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(w.config.ShutdownGrace):
+		log.Printf("Shutdown grace period of %s exceeded, cancelling in-flight runs", w.config.ShutdownGrace)
+		w.cancel()
+		<-done
+	}
+	w.cancel()
+
 	log.Println("Workers stopped.")
 }
 
-func (w *Worker) work(id int) {
+// addWorker starts a worker in the lowest free slot. It must be called with mu held.
+func (w *Worker) addWorker() {
+	id := 0
+	for w.slots[id] != nil {
+		id++
+	}
+
+	s := &slot{stop: make(chan struct{})}
+	w.slots[id] = s
+	metrics.SetWorkers(w.regionCode, w.size())
+
+	w.wg.Add(1)
+	go w.work(id, s.stop)
+}
+
+// removeWorker stops the worker in the highest slot once its current job is done.
+// It must be called with mu held.
+func (w *Worker) removeWorker() {
+	id := -1
+	for i, s := range w.slots {
+		if !s.stopping && i > id {
+			id = i
+		}
+	}
+	if id < 0 {
+		return
+	}
+
+	w.slots[id].stopping = true
+	close(w.slots[id].stop)
+	metrics.SetWorkers(w.regionCode, w.size())
+}
+
+// size returns the number of workers that aren't stopping. It must be called
+// with mu held.
+func (w *Worker) size() int {
+	n := 0
+	for _, s := range w.slots {
+		if !s.stopping {
+			n++
+		}
+	}
+	return n
+}
+
+func (w *Worker) work(id int, stop <-chan struct{}) {
 	defer w.wg.Done()
-	ctx := context.Background()
+	ctx := w.ctx
+
+	// Each worker has its own processing list, named so it is unique across processes
+	hostname, _ := os.Hostname()
+	consumer := fmt.Sprintf("%s-%d-%d", hostname, os.Getpid(), id)
+
+	defer func() {
+		if err := w.redis.UnregisterConsumer(w.regionCode, consumer); err != nil {
+			log.Printf("Worker %d: Error unregistering consumer: %v", id, err)
+		}
+
+		w.mu.Lock()
+		delete(w.slots, id)
+		w.mu.Unlock()
+	}()
 
 	for {
 		select {
 		case <-w.quit:
 			return
+		case <-stop:
+			return
 		default:
-			// Dequeue job with 5 second timeout
-			checkID, err := w.redis.DequeueJob(5 * time.Second)
+			// Dequeue a job of this region with 5 second timeout
+			job, err := w.redis.DequeueJob(w.regionCode, consumer, 5*time.Second)
+			if err != nil {
+				if !errors.Is(err, redis.ErrNoJob) {
+					log.Printf("Worker %d: Error dequeuing job: %v", id, err)
+				}
+				continue
+			}
+
+			// Acknowledge processed jobs, hand failed ones back for another attempt
+			// and release cancelled ones for another worker
+			processed := make(chan struct{})
+			go w.extendLease(job, id, processed)
+			err = w.processCheck(ctx, job, id)
+			close(processed)
+			if errors.Is(err, errRunCancelled) {
+				log.Printf("Worker %d: Releasing job %s for check %s", id, job.ID, job.CheckID)
+				if err := w.redis.ReleaseJob(job); err != nil {
+					log.Printf("Worker %d: Error releasing job %s: %v", id, job.ID, err)
+				}
+				continue
+			}
 			if err != nil {
-				// No job available, continue
+				log.Printf("Worker %d: Retrying job %s for check %s: %v", id, job.ID, job.CheckID, err)
+				if err := w.redis.RetryJob(job, err.Error()); err != nil {
+					log.Printf("Worker %d: Error retrying job %s: %v", id, job.ID, err)
+				}
 				continue
 			}
+			if err := w.redis.AckJob(job); err != nil {
+				log.Printf("Worker %d: Error acknowledging job %s: %v", id, job.ID, err)
+			}
+		}
+	}
+}
+
+// extendLease extends the lease of a job every leaseInterval until it is processed
+func (w *Worker) extendLease(job *redis.Job, workerID int, processed <-chan struct{}) {
+	ticker := time.NewTicker(leaseInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-processed:
+			return
+		case <-ticker.C:
+			if err := w.redis.ExtendJobLease(job); err != nil {
+				log.Printf("Worker %d: Error extending lease of job %s: %v", workerID, job.ID, err)
+			}
+		}
+	}
+}
+
+// autoscale periodically resizes the pool to the queue depth. The pool grows at
+// once to the size that works off the queued jobs within drainTarget at the
+// average run duration, and shrinks one worker at a time, so it doesn't flap
+// while the queue is kept empty.
+func (w *Worker) autoscale() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(scaleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.quit:
+			return
+		case <-ticker.C:
+			depth, err := w.redis.GetQueueDepth(w.regionCode)
+			if err != nil {
+				log.Printf("Error getting queue depth: %v", err)
+				continue
+			}
+
+			w.mu.Lock()
+			current := w.size()
+			desired := w.desiredSize(depth)
+			switch {
+			case desired > current:
+				for i := current; i < desired; i++ {
+					w.addWorker()
+				}
+				log.Printf("Scaled workers for region %s up to %d, %d jobs queued", w.regionCode, desired, depth)
+			case desired < current:
+				w.removeWorker()
+				log.Printf("Scaled workers for region %s down to %d, %d jobs queued", w.regionCode, current-1, depth)
+			}
+			w.mu.Unlock()
+		}
+	}
+}
+
+// desiredSize returns the pool size for the given queue depth within the configured
+// bounds. It must be called with mu held.
+func (w *Worker) desiredSize(depth int64) int {
+	runDuration := w.avgRunDuration
+	if runDuration <= 0 {
+		runDuration = defaultRunDuration
+	}
+
+	desired := int(math.Ceil(float64(depth) * float64(runDuration) / float64(drainTarget)))
+	return min(max(desired, w.config.MinWorkers), w.config.MaxWorkers)
+}
+
+// recordRunDuration folds the duration of a run into the average run duration
+func (w *Worker) recordRunDuration(d time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.avgRunDuration <= 0 {
+		w.avgRunDuration = d
+		return
+	}
+	w.avgRunDuration += time.Duration(runDurationWeight * float64(d-w.avgRunDuration))
+}
+
+// reap periodically requeues jobs whose worker died before acknowledging them
+func (w *Worker) reap() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(reapInterval)
+	defer ticker.Stop()
 
-			w.processCheck(ctx, checkID, id)
+	for {
+		select {
+		case <-w.quit:
+			return
+		case <-ticker.C:
+			requeued, err := w.redis.RequeueExpiredJobs(w.regionCode)
+			if err != nil {
+				log.Printf("Error requeueing expired jobs: %v", err)
+				continue
+			}
+			if requeued > 0 {
+				log.Printf("Requeued %d expired jobs for region %s", requeued, w.regionCode)
+			}
 		}
 	}
 }
 
-func (w *Worker) processCheck(ctx context.Context, checkID uuid.UUID, workerID int) {
+// processCheck runs the check of a job and records the run. It returns an error
+// when the job should be retried; jobs that can never succeed are skipped.
+func (w *Worker) processCheck(ctx context.Context, job *redis.Job, workerID int) error {
 	metrics.IncrementActiveJobs()
 	defer metrics.DecrementActiveJobs()
 
+	checkID := job.CheckID
+	if job.RegionCode != w.regionCode {
+		log.Printf("Worker %d: Job for check %s belongs to region %s, not %s, skipping", workerID, checkID, job.RegionCode, w.regionCode)
+		return nil
+	}
+
+	// Test jobs carry an unsaved check, whose run is handed back rather than stored
+	if job.Check != nil {
+		return w.testCheck(ctx, job, workerID)
+	}
+
 	// Load check from database
 	check, err := w.store.GetCheck(checkID)
 	if err != nil {
-		log.Printf("Worker %d: Error loading check %s: %v", workerID, checkID, err)
-		return
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("Worker %d: Check %s no longer exists, skipping", workerID, checkID)
+			return nil
+		}
+		return fmt.Errorf("loading check: %w", err)
 	}
 
-	// Verify check has this region enabled
+	// The region may have been removed from the check since the job was enqueued
 	hasRegion := false
 	for _, region := range check.Regions {
 		if region.ID == w.regionID {
@@ -94,29 +415,100 @@ func (w *Worker) processCheck(ctx context.Context, checkID uuid.UUID, workerID i
 	}
 	if !hasRegion {
 		log.Printf("Worker %d: Check %s does not have region %s enabled, skipping", workerID, checkID, w.regionID)
-		return
+		return nil
+	}
+
+	// A redelivered manual run may already have been stored
+	if job.RunID != uuid.Nil {
+		runs, err := w.store.GetCheckRunsByIDs(checkID, []uuid.UUID{job.RunID})
+		if err != nil {
+			return fmt.Errorf("loading check run: %w", err)
+		}
+		if len(runs) > 0 {
+			return nil
+		}
 	}
 
 	// Track run start time
 	runStartedAt := time.Now().UTC()
 
 	// Execute check
-	result := checker.Execute(check)
-	metrics.IncrementChecksExecuted()
+	result := checker.Execute(ctx, check)
+
+	// A run cut short by shutdown is not recorded, so it doesn't count as a failure
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return errRunCancelled
+	}
 
 	// Track run end time
 	runEndedAt := time.Now().UTC()
+	w.recordRunDuration(runEndedAt.Sub(runStartedAt))
+	metrics.ObserveCheckRun(string(check.Type), w.regionCode, runEndedAt.Sub(runStartedAt), result.Status == models.CheckRunStatusPassing)
 
-	// Track failures
-	if result.Status != models.CheckRunStatusPassing {
-		metrics.IncrementChecksFailed()
+	checkRun := w.newCheckRun(check, result, runStartedAt, runEndedAt)
+	if job.RunID != uuid.Nil {
+		checkRun.ID = job.RunID
 	}
 
-	// Convert checker.Result to models.CheckRun
-	checkRun := &models.CheckRun{
+	// Runs during maintenance are recorded but excluded from alerts and uptime
+	inMaintenance, err := w.store.IsCheckInMaintenance(check.ID, check.ProjectID, runStartedAt)
+	if err != nil {
+		log.Printf("Worker %d: Error checking maintenance windows for %s: %v", workerID, checkID, err)
+	}
+	checkRun.InMaintenance = inMaintenance
+
+	createdRun, err := w.store.CreateCheckRun(checkRun)
+	if err != nil {
+		return fmt.Errorf("saving check run: %w", err)
+	}
+
+	if w.runs != nil {
+		w.runs.Write(clickhouse.NewCheckRun(createdRun, check, w.regionCode))
+	}
+
+	// Let clients following the check know the run is stored
+	if err := w.redis.PublishCheckRun(check.ID, createdRun.ID); err != nil {
+		log.Printf("Worker %d: Error publishing check run for %s: %v", workerID, checkID, err)
+	}
+
+	// Process alerts
+	w.alerter.ProcessCheckResult(check, createdRun)
+
+	// Update check status, the scheduler already set the next run
+	if err := w.store.UpdateCheckRegionStatus(checkID, w.regionID, nil, result.Status); err != nil {
+		log.Printf("Worker %d: Error updating check status for %s: %v", workerID, checkID, err)
+	}
+
+	log.Printf("Worker %d: Check %s executed: %s", workerID, check.Name, result.Status)
+	return nil
+}
+
+// testCheck runs the unsaved check of a test job and hands the run back to the
+// caller. Nothing is stored and no alerts are processed.
+func (w *Worker) testCheck(ctx context.Context, job *redis.Job, workerID int) error {
+	runStartedAt := time.Now().UTC()
+	result := checker.Execute(ctx, job.Check)
+	runEndedAt := time.Now().UTC()
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return errRunCancelled
+	}
+
+	checkRun := w.newCheckRun(job.Check, result, runStartedAt, runEndedAt)
+	if err := w.redis.PushJobResult(job.ID, checkRun); err != nil {
+		return fmt.Errorf("returning test run: %w", err)
+	}
+
+	log.Printf("Worker %d: Test of check %s executed: %s", workerID, job.Check.Name, result.Status)
+	return nil
+}
+
+// newCheckRun converts the result of a check into a run of this region
+func (w *Worker) newCheckRun(check *models.Check, result checker.Result, runStartedAt, runEndedAt time.Time) *models.CheckRun {
+	return &models.CheckRun{
 		Status:             result.Status,
 		FailureReason:      result.FailureReason,
 		ResponseStatusCode: result.ResponseStatus,
+		Inverted:           result.Inverted,
 
 		// Run timeline
 		RunStartedAt: runStartedAt,
@@ -138,30 +530,10 @@ func (w *Worker) processCheck(ctx context.Context, checkID uuid.UUID, workerID i
 		PlaywrightReport: result.PlaywrightReport,
 		NetworkTimings:   result.NetworkTimings,
 		Response:         result.Response,
+		StepResults:      result.StepResults,
+		ScriptLogs:       result.ScriptLogs,
 
 		RegionID: w.regionID,
 		CheckID:  check.ID,
 	}
-
-	createdRun, err := w.store.CreateCheckRun(checkRun)
-	if err != nil {
-		log.Printf("Worker %d: Error saving check run for %s: %v", workerID, checkID, err)
-		return
-	}
-
-	// Process alerts
-	w.alerter.ProcessCheckResult(check, createdRun)
-
-	// Parse interval and update check status
-	interval, err := time.ParseDuration(check.Interval)
-	if err != nil {
-		log.Printf("Worker %d: Error parsing interval for check %s: %v", workerID, checkID, err)
-		interval = 10 * time.Minute // Default to 10 minutes if parsing fails
-	}
-	nextRun := time.Now().UTC().Add(interval)
-	if err := w.store.UpdateCheckStatus(checkID, nextRun, result.Status); err != nil {
-		log.Printf("Worker %d: Error updating check status for %s: %v", workerID, checkID, err)
-	}
-
-	log.Printf("Worker %d: Check %s executed: %s", workerID, check.Name, result.Status)
 }